@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// cleanCommand implements "gengen clean", which removes the files that were generated from the manifests and
+// go:generate lines of a directory, but are no longer generated by them.
+func cleanCommand(args []string) {
+	var dryRun bool
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	fs.BoolVar(&dryRun, "n", false, "Print the files that would be removed, without removing them.")
+	fs.StringVar(&opts.Shared, "shared", "gengen_shared.go", "The shared file of the directories of the go files, which the files removed are also removed from.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen clean [-n] [<directory>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	x, err := gengen.LoadOutputIndex(getRealPath(dir))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, source := range x.Sources() {
+		stale, err := x.Stale(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, path := range stale {
+			prune(x, path, dryRun)
+		}
+	}
+	if !dryRun {
+		if err = x.Save(); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// recordOutputs adds the files that source generated to the index of dir. If the -prune option is set, it
+// also removes the files that source generated before, but no longer does.
+func recordOutputs(dir, source string, outputs []string) {
+	x, err := gengen.LoadOutputIndex(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	x.Add(source, outputs...)
+	if opts.Prune {
+		stale, err := x.Stale(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, path := range stale {
+			prune(x, path, false)
+		}
+	}
+	if err = x.Save(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// prune removes a generated file that is no longer generated, along with its sections of the shared file of
+// its directory, and drops it from the index. A file that does not have the gengen header is left alone,
+// since someone has taken it over.
+func prune(x *gengen.OutputIndex, path string, dryRun bool) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		x.Remove(path)
+		return
+	} else if err != nil {
+		log.Fatal(err)
+	}
+	if !gengen.IsGenerated(data) {
+		log.Printf("warning: %s is no longer generated, but was not removed because it does not have the gengen header", path)
+		x.Remove(path)
+		return
+	}
+	fmt.Println("removing", path)
+	if dryRun {
+		return
+	}
+
+	files := map[string][]byte{path: nil}
+	if isShared(path) {
+		shared := filepath.Join(filepath.Dir(path), opts.Shared)
+		files[shared] = updateShared(shared, path, nil, nil)
+	}
+	for p, data := range files {
+		writeOutput(p, data)
+	}
+	x.Remove(path)
+}
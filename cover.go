@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"github.com/goradd/gengen/pkg/gengen"
+	"log"
+	"os"
+)
+
+// coverCommand implements "gengen cover", which reports on a coverage profile made with the -cover option.
+func coverCommand(args []string) {
+	fs := flag.NewFlagSet("cover", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte("usage: gengen cover <profile>\n"))
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	cover, err := gengen.LoadCoverage(getRealPath(fs.Arg(0)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	cover.Report(os.Stdout)
+}
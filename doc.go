@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// docCommand implements "gengen doc", which documents the templates of the Library, or of the given
+// directories, as Markdown or as an HTML page.
+func docCommand(args []string) {
+	var outFile, title string
+	var html bool
+	var lines int
+	fs := flag.NewFlagSet("doc", flag.ExitOnError)
+	fs.StringVar(&outFile, "o", "", "Output file. If not specified, the documentation is sent to stdout.")
+	fs.BoolVar(&html, "html", false, "Write an HTML page instead of Markdown.")
+	fs.StringVar(&title, "title", "Templates", "The title of the documentation.")
+	fs.IntVar(&lines, "lines", 30, "The number of lines of the sample output of each template. Zero means all of them.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen doc [-o <file>] [-html] [-title <title>] [-lines <n>] [<template_directory>...]")
+		fmt.Fprintln(fs.Output(), "Without directories, the templates of the Library are documented.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	// each template is named as it is referred to
+	names := make(map[string]string)
+	if fs.NArg() == 0 {
+		for collection := range gengen.Library {
+			dir, err := gengen.LibraryDir(collection)
+			if err != nil {
+				log.Fatal(err)
+			}
+			files, _ := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+			for _, path := range files {
+				names[path] = "lib:" + collection + "/" + trimExt(filepath.Base(path))
+			}
+		}
+	} else {
+		for _, dir := range fs.Args() {
+			files, err := filepath.Glob(filepath.Join(getRealPath(dir), "*.tmpl"))
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, path := range files {
+				names[path] = filepath.ToSlash(filepath.Join(dir, filepath.Base(path)))
+			}
+		}
+	}
+	if len(names) == 0 {
+		log.Fatal("there are no templates to document")
+	}
+	paths := make([]string, 0, len(names))
+	for path := range names {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool { return names[paths[i]] < names[paths[j]] })
+
+	var docs []*gengen.TemplateDoc
+	for _, path := range paths {
+		d, err := gengen.DocumentTemplate(names[path], path, lines)
+		if err != nil {
+			log.Fatal(err)
+		}
+		docs = append(docs, d)
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if html {
+		err = gengen.WriteHTML(&buf, title, docs)
+	} else {
+		err = gengen.WriteMarkdown(&buf, title, docs)
+	}
+	if err == nil {
+		if outFile == "" {
+			_, err = os.Stdout.Write(buf.Bytes())
+		} else {
+			err = ioutil.WriteFile(outFile, buf.Bytes(), 0644)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// trimExt returns the name of a file without its extension.
+func trimExt(name string) string {
+	return name[:len(name)-len(filepath.Ext(name))]
+}
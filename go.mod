@@ -1,3 +1,7 @@
 module github.com/goradd/gengen
 
+go 1.18
+
 require github.com/goradd/gofile v0.1.6
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// impactCommand implements "gengen impact", which shows how a change to templates changes the files they
+// generate, by running the jobs of a manifest, or of the go:generate lines of some directories, with both the
+// old and new versions of the templates.
+func impactCommand(args []string) {
+	var oldVersion, newDir, manifest string
+	var showDiff bool
+	fs := flag.NewFlagSet("impact", flag.ExitOnError)
+	fs.StringVar(&oldVersion, "old", "", "The old version of the templates, either a directory or a git revision of the directory of the new version.")
+	fs.StringVar(&newDir, "new", "", "The directory of the new version of the templates.")
+	fs.StringVar(&manifest, "m", "", "A manifest whose jobs are run, instead of the go:generate lines of the directories.")
+	fs.BoolVar(&showDiff, "diff", true, "Show the differences in each output that changes.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen impact -old <revision_or_directory> -new <directory> [-m <manifest> | <directory>...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if oldVersion == "" || newDir == "" || manifest != "" && fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	newDir = getRealPath(newDir)
+
+	var jobs []gengen.Job
+	if manifest != "" {
+		m, err := gengen.LoadManifest(getRealPath(manifest))
+		if err != nil {
+			log.Fatal(err)
+		}
+		jobs = m.Jobs
+	} else {
+		dirs := fs.Args()
+		if len(dirs) == 0 {
+			dirs = []string{"."}
+		}
+		for _, dir := range dirs {
+			jobs = append(jobs, generateJobs(getRealPath(dir))...)
+		}
+	}
+	if len(jobs) == 0 {
+		log.Fatal("no jobs were found")
+	}
+
+	oldDir := oldVersion
+	if info, err := os.Stat(oldVersion); err != nil || !info.IsDir() {
+		tmp, err := ioutil.TempDir("", "gengen-impact")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer os.RemoveAll(tmp)
+		if err = checkout(newDir, oldVersion, tmp); err != nil {
+			os.RemoveAll(tmp)
+			log.Fatal(err)
+		}
+		oldDir = tmp
+	}
+
+	impacts, err := gengen.CompareTemplates(jobs, oldDir, newDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	var changed int
+	for _, i := range impacts {
+		name := i.Output
+		if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+		switch {
+		case i.NewErr != nil:
+			fmt.Printf("failed     %s: %s\n", name, i.NewErr)
+		case i.OldErr != nil:
+			fmt.Printf("new        %s (the old version failed: %s)\n", name, i.OldErr)
+		case i.Changed():
+			fmt.Printf("changed    %s\n", name)
+		default:
+			fmt.Printf("unchanged  %s\n", name)
+		}
+		for _, s := range i.Removed {
+			fmt.Printf("    - %s\n", s)
+		}
+		for _, s := range i.Added {
+			fmt.Printf("    + %s\n", s)
+		}
+		if i.Changed() {
+			changed++
+		}
+	}
+	fmt.Printf("%d of %d outputs change\n", changed, len(impacts))
+
+	if showDiff {
+		for _, i := range impacts {
+			if i.OldErr == nil && i.NewErr == nil && i.Changed() {
+				fmt.Print("\n" + gengen.Diff("old/"+filepath.Base(i.Output), "new/"+filepath.Base(i.Output), i.Old, i.New))
+			}
+		}
+	}
+}
+
+// generateJobs returns the jobs of the go:generate lines in dir and the directories under it.
+func generateJobs(dir string) (jobs []gengen.Job) {
+	err := walkPackages(dir, func(path string) error {
+		j, err := gengen.GenerateJobs(path)
+		jobs = append(jobs, j...)
+		return err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+// walkPackages calls fn with dir and each directory under it, except hidden directories, and vendor and
+// testdata directories, which go generate also skips.
+func walkPackages(dir string, fn func(dir string) error) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		name := info.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata") {
+			return filepath.SkipDir
+		}
+		return fn(path)
+	})
+}
+
+// checkout extracts the files of dir, which is in a git repository, as they were at a revision, into dest.
+func checkout(dir, revision, dest string) error {
+	prefix, err := git(dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return err
+	}
+	top, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return err
+	}
+	// git archive works from the top of the repository, with the directory named by the tree it archives
+	archive, err := git(strings.TrimSpace(string(top)), "archive", "--format=tar", revision+":"+strings.TrimSpace(string(prefix)))
+	if err != nil {
+		return err
+	}
+	r := tar.NewReader(bytes.NewReader(archive))
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		path := filepath.Join(dest, filepath.FromSlash(h.Name))
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(path, 0755)
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+				var data []byte
+				if data, err = ioutil.ReadAll(r); err == nil {
+					err = ioutil.WriteFile(path, data, 0644)
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// git runs a git command in dir and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// initCommand implements "gengen init", which starts a new collection, like a map with a particular key and
+// value type, in a package, and generates it and its test.
+func initCommand(args []string) {
+	var kinds []string
+	for kind := range gengen.ScaffoldKinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	var s gengen.Scaffold
+	var run bool
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	fs.StringVar(&s.Key, "key", "string", "The go type of the keys.")
+	fs.StringVar(&s.Val, "val", "interface{}", "The go type of the values, which can be a type of the package, like *User.")
+	fs.BoolVar(&s.Safe, "safe", false, "Make a collection that is synchronized with a sync.RWMutex.")
+	fs.StringVar(&s.Dir, "pkg", ".", "The directory of the package to put the collection in.")
+	fs.StringVar(&s.TypeName, "name", "", "The name of the type of the collection. By default it is named after the key and value types.")
+	fs.StringVar(&s.GoFile, "file", "gengen.go", "The file of the package to add the go:generate lines to.")
+	fs.BoolVar(&run, "run", true, "Generate the collection and its test after writing their configurations.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gengen init [options] <%s>\n", strings.Join(kinds, "|"))
+		fs.PrintDefaults()
+	}
+	// the kind can come before the options, as in "gengen init slicemap -key string"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		s.Kind = args[0]
+		args = args[1:]
+	}
+	fs.Parse(args)
+	if s.Kind == "" && fs.NArg() == 1 {
+		s.Kind = fs.Arg(0)
+	} else if s.Kind == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	s.Dir = filepath.Clean(s.Dir)
+
+	lines, err := s.Write()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, genArgs := range lines {
+		fmt.Println("wrote", filepath.Join(s.Dir, genArgs[1]))
+		fmt.Println("added //go:generate gengen", strings.Join(genArgs, " "), "to", filepath.Join(s.Dir, s.GoFile))
+	}
+	if !run {
+		return
+	}
+
+	// run the lines the way go generate would
+	self, err := os.Executable()
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, genArgs := range lines {
+		cmd := exec.Command(self, genArgs...)
+		cmd.Dir = s.Dir
+		cmd.Env = append(os.Environ(), "GOFILE="+s.GoFile)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err = cmd.Run(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("generated", filepath.Join(s.Dir, genArgs[3]))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+// lintCommand implements "gengen lint", which checks a template against the config files that drive it.
+// It exits with a status of 1 if it finds any issues.
+func lintCommand(args []string) {
+	var configs gengen.ListFlag
+	var delims string
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	fs.Var(&configs, "c", "A config file used with the template. Repeat to check the template against several config files.")
+	fs.StringVar(&delims, "delims", "", "The left and right delimiters of the template, separated by a space. Front matter in the template overrides this.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen lint -c <config_file> [-c <config_file>...] <template_file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 || len(configs) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name, err := gengen.ResolveTemplate(fs.Arg(0), "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tmpl, err := gengen.ParseTemplateOptions(name, string(data), gengen.Options{Delims: delims})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	texts := make(map[string][]byte)
+	for _, c := range configs {
+		c = getRealPath(c)
+		if texts[c], err = ioutil.ReadFile(c); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	issues := tmpl.Lint(texts)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/lsp"
+	"log"
+	"os"
+)
+
+// lspCommand implements "gengen lsp", which runs a language server for templates over stdin and stdout.
+func lspCommand(args []string) {
+	fs := flag.NewFlagSet("lsp", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen lsp")
+		fmt.Fprintln(fs.Output(), "Runs a language server for gengen templates, which editors talk to over stdin and stdout.")
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -2,96 +2,390 @@ package main
 
 import (
 	"bytes"
-	"encoding/json"
 	"flag"
-	"github.com/goradd/gofile/pkg/sys"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"text/template"
+	"strings"
 )
 
-var modules map[string]string
+// opts are the options of the command line.
+var opts gengen.Flags
+
+// deps collects the rules that are written to the file of the -deps option.
+var deps bytes.Buffer
 
 func main() {
-	var config string
-	var outFile string
 	var err error
 
-	flag.StringVar(&config, "c", "", "A required config file that will be used to provide the *dot* context to the template.")
-	flag.StringVar(&outFile, "o", "", "Output file. If not specified, output will be sent to stdout.")
-	flag.Parse() // regular run of program
-
-	if config == "" {
-		log.Fatal("you must specify a config file with the -c option.")
-	}
-
-	modules, err = sys.ModulePaths()
-	if err != nil {
-		log.Fatal(err)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "cover":
+			coverCommand(os.Args[2:])
+			return
+		case "lint":
+			lintCommand(os.Args[2:])
+			return
+		case "clean":
+			cleanCommand(os.Args[2:])
+			return
+		case "templatize":
+			templatizeCommand(os.Args[2:])
+			return
+		case "serve":
+			serveCommand(os.Args[2:])
+			return
+		case "lsp":
+			lspCommand(os.Args[2:])
+			return
+		case "impact":
+			impactCommand(os.Args[2:])
+			return
+		case "vendor":
+			vendorCommand(os.Args[2:])
+			return
+		case "init":
+			initCommand(os.Args[2:])
+			return
+		case "doc":
+			docCommand(os.Args[2:])
+			return
+		case "version":
+			versionCommand(os.Args[2:])
+			return
+		}
 	}
 
-	config = getRealPath(config)
+	opts.Define(flag.CommandLine)
+	flag.Parse() // regular run of program
 
-	data, err := ioutil.ReadFile(config)
-	if err != nil {
-		panic(err)
+	if opts.Manifest != "" {
+		if opts.Config != "" || opts.Output != "" || opts.Data != nil || flag.NArg() > 0 {
+			log.Fatal("a manifest cannot be used with the -c, -o or -data options or a template file")
+		}
+		path := getRealPath(opts.Manifest)
+		outputs := runManifest(path)
+		recordOutputs(filepath.Dir(path), path, outputs)
+		writeDeps()
+		return
 	}
 
-	var dot interface{}
-
-	idx := bytes.IndexRune(data, '{')
-	if idx < 0 {
-		panic ("The configuration file must contain a json object that starts with an open bracket.")
+	if opts.Prune && opts.Output == "" {
+		log.Fatal("the -prune option requires a manifest or an output file")
 	}
-	err = json.Unmarshal(data[idx:], &dot)
-	if err != nil {
-		panic(err)
+	if opts.Config == "" {
+		log.Fatal("you must specify a config file with the -c option.")
 	}
 
+	var data []byte
+	var name string
 	switch flag.NArg() {
 	case 0:
+		name = "stdin"
 		data, err = ioutil.ReadAll(os.Stdin)
 		if err != nil {panic(err)}
 		break
 	case 1:
-		data, err = ioutil.ReadFile(getRealPath(flag.Arg(0)))
+		if name, err = gengen.ResolveTemplate(flag.Arg(0), ""); err != nil {
+			log.Fatal(err)
+		}
+		data, err = ioutil.ReadFile(name)
 		if err != nil {panic(err)}
 		break
 	default:
 		log.Fatal("input must be from stdin or a single file")
 	}
 
-	var tmpl *template.Template
-	tmpl,err = template.New("temp").Parse(string(data))
-	if err != nil {log.Fatal(err)}
+	job := gengen.Job{Template: name, Config: getRealPath(opts.Config), Delims: opts.Delims}
+	for _, d := range opts.Data {
+		i := strings.Index(d, "=")
+		if i <= 0 {
+			log.Fatalf("data file %q must be given as name=path", d)
+		}
+		if job.Data == nil {
+			job.Data = make(map[string]string)
+		}
+		job.Data[d[:i]] = getRealPath(d[i+1:])
+	}
+
+	if opts.Output == "" {
+		if opts.Deps != "" {
+			log.Fatal("the -deps option requires an output file")
+		}
+		t := prepare(job, data)
+		out, _ := t.execute(false)
+		if out, err = gengen.Assemble("", []gengen.Part{{Name: t.Template, Out: out}}, t.Processors); err != nil {
+			log.Fatal(err)
+		}
+		os.Stdout.Write(out)
+		return
+	}
+	job.Output = getRealPath(opts.Output)
+	build(job.Output, []task{prepare(job, data)})
+	writeDeps()
+	// runs without a manifest, which go generate and make can do in parallel, only record their output when asked to
+	if opts.Prune {
+		wd, err := os.Getwd()
+		if err != nil {
+			log.Fatal(err)
+		}
+		recordOutputs(wd, gengen.CommandLine, []string{job.Output})
+	}
+}
+
+// runManifest runs the jobs of a manifest, combining the jobs that have the same output file into it. It returns
+// the output files.
+func runManifest(path string) []string {
+	m, err := gengen.LoadManifest(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputs, jobs := m.Outputs()
+	for _, outFile := range outputs {
+		var tasks []task
+		for _, job := range jobs[outFile] {
+			data, err := ioutil.ReadFile(job.Template)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if job.Delims == "" {
+				job.Delims = opts.Delims
+			}
+			tasks = append(tasks, prepare(job, data))
+		}
+		build(outFile, tasks)
+	}
+	return outputs
+}
+
+// build runs the tasks that produce outFile and writes their combined output to it, unless the cache shows
+// that outFile, and the sections of the shared file it uses, already hold the output of the same inputs. It
+// also adds the rule of outFile to deps.
+func build(outFile string, tasks []task) {
+	var inputs []string
+	var settings []string
+	seen := make(map[string]bool)
+	for _, t := range tasks {
+		for _, path := range t.Inputs(t.Parsed) {
+			if path == "stdin" && t.Template == "stdin" {
+				// the text of the template takes the place of its file
+				settings = append(settings, string(t.text))
+				continue
+			}
+			if !seen[path] {
+				seen[path] = true
+				inputs = append(inputs, path)
+			}
+		}
+		settings = append(settings, fmt.Sprintf("%#v", t.Job))
+	}
+	if opts.Deps != "" {
+		if deps.Len() > 0 {
+			deps.WriteString("\n")
+		}
+		gengen.WriteDeps(&deps, outFile, inputs)
+	}
+
+	var key, shared string
+	if isShared(outFile) {
+		shared = filepath.Join(filepath.Dir(outFile), opts.Shared)
+	}
+	cache := gengen.Cache{Dir: opts.Cache}
+	if opts.Cache != "" && opts.Cover == "" && !opts.Trace {
+		var err error
+		if key, err = cache.Key(inputs, append(settings, opts.Shared)...); err != nil {
+			log.Fatal(err)
+		}
+		if cache.Fresh(key, outFile, shared) {
+			return
+		}
+	}
+
+	var parts []gengen.Part
+	var sections []gengen.Section
+	var processors [][]gengen.Processor
+	for i, t := range tasks {
+		out, s := t.execute(isShared(outFile))
+		name := t.Template
+		if len(tasks) > 1 {
+			name = fmt.Sprintf("part %d (%s)", i+1, t.Job)
+		}
+		parts = append(parts, gengen.Part{Name: name, Out: out})
+		sections = append(sections, s...)
+		processors = append(processors, t.Processors)
+	}
+	writeOutputs(outFile, parts, sections, gengen.OutputProcessors(processors...))
+
+	if key != "" {
+		if err := cache.Save(key, outFile, shared); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// writeDeps writes the rules collected in deps to the file of the -deps option.
+func writeDeps() {
+	if opts.Deps == "" {
+		return
+	}
+	if err := gengen.WriteFile(getRealPath(opts.Deps), deps.Bytes()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// isShared returns whether the sections produced with the shared function go in a shared file when
+// generating outFile.
+func isShared(outFile string) bool {
+	return opts.Shared != "" && strings.HasSuffix(outFile, ".go")
+}
+
+// A task is a job whose template is parsed and whose dot context is loaded, ready to be executed.
+type task struct {
+	*gengen.Prepared
+	text []byte
+}
+
+// prepare parses the template of a job, whose text is in data, and loads its dot context.
+func prepare(job gengen.Job, data []byte) task {
+	dot, err := gengen.LoadConfig(job.Config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	p, err := job.Prepare(dot, string(data), gengen.Options{Limits: opts.Limits, Restricted: opts.Restricted})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = gengen.CheckLocks(job.Inputs(p.Parsed)...); err != nil {
+		log.Fatal(err)
+	}
+	return task{Prepared: p, text: data}
+}
+
+// execute executes the template of the task. If shared is true, the sections produced with the shared
+// function are returned instead of being put in the output.
+func (t task) execute(shared bool) ([]byte, []gengen.Section) {
+	var monitors []gengen.Monitor
+	var counts gengen.Counter
+	var tracer *gengen.Tracer
+	if opts.Cover != "" {
+		counts = make(gengen.Counter)
+		monitors = append(monitors, counts)
+	}
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	if opts.Trace {
+		tracer = gengen.NewTracer(out)
+		monitors = append(monitors, tracer)
+		out = tracer
+	}
+
+	var probes []gengen.Probe
+	if monitors != nil {
+		probes = t.Parsed.Instrument(monitors...)
+	}
+
+	sections, err := t.Execute(out, shared)
+	if tracer != nil {
+		tracer.Report(os.Stderr, probes)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if outFile == "" {
-		err = tmpl.Execute(os.Stdout, dot)
+	if opts.Cover != "" {
+		path := getRealPath(opts.Cover)
+		cover, err := gengen.LoadCoverage(path)
 		if err != nil {log.Fatal(err)}
-	} else {
-		if file,err := os.Create(getRealPath(outFile)); err != nil {
-			panic(err)
-		} else {
-			defer file.Close()
-			err = tmpl.Execute(file, dot)
-			if err != nil {panic(err)}
+		cover.Add(probes, counts)
+		if err = cover.Save(path); err != nil {log.Fatal(err)}
+	}
+	return buf.Bytes(), sections
+}
+
+// writeOutputs combines the outputs of the jobs that produce outFile, runs the result through processors and
+// writes it to outFile, along with the shared file of its directory.
+func writeOutputs(outFile string, parts []gengen.Part, sections []gengen.Section, processors []gengen.Processor) {
+	out, err := gengen.Assemble(outFile, parts, processors)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// the regions are kept after processing, since the file already holds their processed content
+	out = keepRegions(outFile, out)
+	files := map[string][]byte{outFile: out}
+	if isShared(outFile) {
+		path := filepath.Join(filepath.Dir(outFile), opts.Shared)
+		files[path] = updateShared(path, outFile, files[outFile], sections)
+	}
+	if strings.HasSuffix(outFile, ".go") {
+		if err := gengen.CheckDeclarations(files); err != nil {
+			log.Fatal(err)
 		}
 	}
+	for path, data := range files {
+		writeOutput(path, data)
+	}
 }
 
-func getRealPath(path string) string {
-	var err error
-	path = os.ExpandEnv(path)
-	path,err = sys.GetModulePath(path, modules)
+// keepRegions returns generated output with the content of the protected regions of the file at path,
+// if it exists, put back in.
+func keepRegions(path string, out []byte) []byte {
+	old, err := ioutil.ReadFile(path)
+	if err != nil {
+		return out
+	}
+	var lost map[string]string
+	if out, lost, err = gengen.KeepRegions(out, old); err != nil {
+		log.Fatalf("%s: %s", path, err)
+	}
+	for name, content := range lost {
+		if strings.TrimSpace(content) == "" {
+			log.Printf("warning: %s: protected region %q is no longer in the template", path, name)
+			continue
+		}
+		log.Printf("warning: %s: protected region %q is no longer in the template, so this content was dropped:\n%s", path, name, content)
+	}
+	return out
+}
+
+// updateShared returns the new content of the shared file at path, given the shared sections that the output
+// file now uses. It returns nil if the shared file is no longer needed.
+func updateShared(path string, outFile string, out []byte, sections []gengen.Section) []byte {
+	f, err := gengen.LoadSharedFile(path)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err = f.Update(filepath.Base(outFile), out, sections); err != nil {
+		log.Fatal(err)
+	}
+	data, err := f.Bytes()
+	if err != nil {
+		log.Fatalf("%s: %s", path, err)
+	}
+	return data
+}
 
-	path, err = filepath.Abs(path)
+// writeOutput writes a generated file, or removes it if data is nil.
+func writeOutput(path string, data []byte) {
+	if data == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := gengen.WriteFile(path, data); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func getRealPath(path string) string {
+	path, err := gengen.RealPath(path)
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
 	return path
 }
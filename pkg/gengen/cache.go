@@ -0,0 +1,88 @@
+package gengen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A Cache remembers the outputs that jobs produced from their inputs, so that a job whose inputs have not
+// changed since it last wrote its output does not need to run again. Entries are files in Dir, named after
+// the hash of the inputs, that hold the hash of the output.
+type Cache struct {
+	Dir string
+}
+
+// Key returns the key of the cache entry for a job, which is a hash of the version of gengen, the names and
+// contents of the input files, and the settings that change what the job produces.
+func (c Cache) Key(inputs []string, settings ...string) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, Version+"\x00")
+	for _, path := range inputs {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, path+"\x00")
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		io.WriteString(h, "\x00")
+	}
+	io.WriteString(h, strings.Join(settings, "\x00"))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Fresh returns true if the file at path holds the output that was saved for key. If shared is not empty, it is
+// the shared file of the package of path, which must also hold the sections of the output that were saved.
+func (c Cache) Fresh(key, path, shared string) bool {
+	want, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil {
+		return false
+	}
+	got, err := outputHash(path, shared)
+	return err == nil && got == string(want)
+}
+
+// Save records that the file at path, and the shared file if it is not empty, hold the output of the job with
+// the given key.
+func (c Cache) Save(key, path, shared string) error {
+	h, err := outputHash(path, shared)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(c.Dir, 0755); err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(c.Dir, key), []byte(h))
+}
+
+// outputHash returns the hash of the file at path, followed by the hash of the sections of the shared file that
+// it uses, if shared is not empty. The rest of the shared file is left out, since other files of the package
+// change it.
+func outputHash(path, shared string) (string, error) {
+	h, err := fileHash(path)
+	if err != nil || shared == "" {
+		return h, err
+	}
+	f, err := LoadSharedFile(shared)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(f.used(filepath.Base(path)))
+	return h + " " + hex.EncodeToString(sum[:]), nil
+}
+
+func fileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,87 @@
+package gengen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// A Part is the output of one of the jobs that are combined into a file.
+type Part struct {
+	// Name identifies the job in errors.
+	Name string
+	Out  []byte
+}
+
+// Combine combines the go files that several jobs produce into one file. The files must be in the same
+// package. The result has one package clause, and one import declaration with the imports of all of the files,
+// followed by the rest of each file in order. Anything before the package clause of the first file, like
+// a build constraint, is kept. Declarations that more than one file makes are reported as an error.
+//
+// The output of a single job is returned as it is.
+func Combine(parts []Part) ([]byte, error) {
+	if len(parts) == 1 {
+		return parts[0].Out, nil
+	}
+
+	var buf bytes.Buffer
+	var pkg string
+	var imports []Import
+	var bodies []string
+	declared := make(map[string]string)
+	dups := make(map[string]bool)
+	named := make(map[string]Import)
+	var problems []string
+	for _, part := range parts {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "", part.Out, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", part.Name, err)
+		}
+		if pkg == "" {
+			pkg = f.Name.Name
+			buf.Write(part.Out[:fset.Position(f.Package).Offset])
+		} else if f.Name.Name != pkg {
+			return nil, fmt.Errorf("%s is in package %s, but %s is in package %s", part.Name, f.Name.Name, parts[0].Name, pkg)
+		}
+
+		for _, d := range declarations(f) {
+			other, ok := declared[d.Name]
+			if !ok {
+				declared[d.Name] = part.Name
+				continue
+			}
+			dups[d.Name] = true
+			// the methods of a type that is declared twice are not worth mentioning
+			if i := strings.LastIndex(d.Name, "."); i < 0 || !dups[d.Name[:i]] {
+				problems = append(problems, fmt.Sprintf("%s is declared by both %s and %s", d.Name, other, part.Name))
+			}
+		}
+
+		i, body, _, _ := splitImports(part.Out)
+		for _, imp := range i {
+			name := imp.localName()
+			if other, ok := named[name]; ok && other.Path != imp.Path && name != "_" && name != "." {
+				problems = append(problems, fmt.Sprintf("%s imports %q as %s, which is also the name of %q", part.Name, imp.Path, name, other.Path))
+				continue
+			}
+			named[name] = imp
+		}
+		imports = append(imports, i...)
+		bodies = append(bodies, strings.TrimSpace(body))
+	}
+	if problems != nil {
+		return nil, errors.New(strings.Join(problems, "\n"))
+	}
+
+	buf.WriteString("package " + pkg + "\n")
+	writeImports(&buf, imports)
+	for _, body := range bodies {
+		buf.WriteString("\n" + body + "\n")
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,37 @@
+package gengen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCombine(t *testing.T) {
+	a := Part{"a", []byte("//go:build !wasm\n\npackage p\n\nimport \"fmt\"\n\nfunc A() { fmt.Println() }\n")}
+	b := Part{"b", []byte("package p\n\nimport (\n\t\"fmt\"\n\t\"sort\"\n)\n\n// B sorts\nfunc B() { fmt.Println(sort.Ints) }\n")}
+	out, err := Combine([]Part{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "//go:build !wasm\n\npackage p\n\nimport (\n\t\"fmt\"\n\t\"sort\"\n)\n\nfunc A() { fmt.Println() }\n\n// B sorts\nfunc B() { fmt.Println(sort.Ints) }\n"
+	if string(out) != expected {
+		t.Errorf("Unexpected output:\n%s", out)
+	}
+
+	c := Part{"c", []byte("package p\n\ntype T int\n\nfunc (T) A() {}\n\nfunc A() {}\n")}
+	_, err = Combine([]Part{a, b, c})
+	if err == nil || err.Error() != "A is declared by both a and c" {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// the methods of generic types with the same names are not duplicates
+	e := Part{"e", []byte("package p\n\ntype E[K comparable, V any] map[K]V\n\nfunc (m E[K, V]) Len() int { return len(m) }\n")}
+	f := Part{"f", []byte("package p\n\ntype F[K comparable, V any] map[K]V\n\nfunc (m *F[K, V]) Len() int { return len(*m) }\n")}
+	if _, err = Combine([]Part{e, f}); err != nil {
+		t.Error(err)
+	}
+
+	d := Part{"d", []byte("package q\n")}
+	if _, err = Combine([]Part{a, d}); err == nil || !strings.Contains(err.Error(), "package q") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadConfig reads the json configuration file at path and returns its contents, ready to be used as the
+// dot context of a template.
+func LoadConfig(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig decodes the json object in data. Anything before the first open bracket is ignored, which lets
+// configuration files start with a comment.
+//
+// Numbers are decoded without losing precision. A whole number becomes an int64, or a uint64 if it is too large
+// for an int64. A whole number too large for both is kept as a json.Number, which prints exactly as it was
+// written. Other numbers become a float64.
+func ParseConfig(data []byte) (interface{}, error) {
+	idx := bytes.IndexRune(data, '{')
+	if idx < 0 {
+		return nil, errors.New("the configuration file must contain a json object that starts with an open bracket")
+	}
+	return decodeJSON(data[idx:])
+}
+
+// decodeJSON decodes a json value, decoding numbers the way ParseConfig describes.
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after the end of the json value")
+	}
+	return numbers(v), nil
+}
+
+// numbers replaces the json numbers in a decoded json value with the go numbers they best fit in.
+func numbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		return number(v)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = numbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = numbers(e)
+		}
+	}
+	return v
+}
+
+func number(n json.Number) interface{} {
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		return u
+	}
+	if !strings.ContainsAny(string(n), ".eE") {
+		return n
+	}
+	f, _ := n.Float64()
+	return f
+}
@@ -0,0 +1,169 @@
+package gengen
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"path/filepath"
+	"strings"
+)
+
+// The operating systems and architectures that go knows, as in go/build.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+		"illumos": true, "ios": true, "js": true, "linux": true, "nacl": true, "netbsd": true, "openbsd": true,
+		"plan9": true, "solaris": true, "wasip1": true, "windows": true, "zos": true,
+	}
+	unixOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "hurd": true,
+		"illumos": true, "ios": true, "linux": true, "netbsd": true, "openbsd": true, "solaris": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true, "arm64be": true,
+		"loong64": true, "mips": true, "mipsle": true, "mips64": true, "mips64le": true, "mips64p32": true,
+		"mips64p32le": true, "ppc": true, "ppc64": true, "ppc64le": true, "riscv": true, "riscv64": true,
+		"s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// maxFreeTags limits the tags other than operating systems and architectures that sameBuild tries all the
+// values of. Constraints with more of them are assumed to be able to hold together.
+const maxFreeTags = 10
+
+// buildConstraint returns the build constraint of a go file, which combines its //go:build line, or the
+// older plus build lines, with what a _GOOS or _GOARCH suffix of its name requires. It returns nil if the file
+// has none.
+func buildConstraint(f *ast.File, path string) (expr constraint.Expr) {
+	and := func(x constraint.Expr) {
+		if expr == nil {
+			expr = x
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: x}
+		}
+	}
+
+	var goBuild constraint.Expr
+	var plusBuild []constraint.Expr
+	for _, g := range f.Comments {
+		if g.Pos() >= f.Package {
+			break
+		}
+		for _, c := range g.List {
+			if constraint.IsGoBuild(c.Text) {
+				if x, err := constraint.Parse(c.Text); err == nil {
+					goBuild = x
+				}
+			} else if constraint.IsPlusBuild(c.Text) {
+				if x, err := constraint.Parse(c.Text); err == nil {
+					plusBuild = append(plusBuild, x)
+				}
+			}
+		}
+	}
+	if goBuild != nil {
+		and(goBuild)
+	} else {
+		for _, x := range plusBuild {
+			and(x)
+		}
+	}
+
+	// the first element of the name is never a tag, as in go/build
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".go"), "_test")
+	parts := strings.Split(name, "_")[1:]
+	if n := len(parts); n > 0 {
+		last := parts[n-1]
+		if n > 1 && knownOS[parts[n-2]] && knownArch[last] {
+			and(&constraint.TagExpr{Tag: parts[n-2]})
+			and(&constraint.TagExpr{Tag: last})
+		} else if knownOS[last] || knownArch[last] {
+			and(&constraint.TagExpr{Tag: last})
+		}
+	}
+	return
+}
+
+// sameBuild returns true if files with the build constraints x and y can be part of the same build. Either
+// can be nil, which means the file has no constraint.
+func sameBuild(x, y constraint.Expr) bool {
+	switch {
+	case x == nil && y == nil:
+		return true
+	case x == nil:
+		return satisfiable(y)
+	case y == nil:
+		return satisfiable(x)
+	}
+	return satisfiable(&constraint.AndExpr{X: x, Y: y})
+}
+
+// satisfiable returns true if there is an operating system, an architecture and a set of other tags for which
+// expr is true.
+func satisfiable(expr constraint.Expr) bool {
+	free := make(map[string]bool)
+	var tags []string
+	var walk func(constraint.Expr)
+	walk = func(x constraint.Expr) {
+		switch x := x.(type) {
+		case *constraint.AndExpr:
+			walk(x.X)
+			walk(x.Y)
+		case *constraint.OrExpr:
+			walk(x.X)
+			walk(x.Y)
+		case *constraint.NotExpr:
+			walk(x.X)
+		case *constraint.TagExpr:
+			if !knownOS[x.Tag] && !knownArch[x.Tag] && x.Tag != "unix" && !free[x.Tag] {
+				free[x.Tag] = true
+				tags = append(tags, x.Tag)
+			}
+		}
+	}
+	walk(expr)
+	if len(tags) > maxFreeTags {
+		return true
+	}
+
+	// an empty name stands for the systems that no tag names
+	oses := []string{""}
+	for os := range knownOS {
+		oses = append(oses, os)
+	}
+	arches := []string{""}
+	for arch := range knownArch {
+		arches = append(arches, arch)
+	}
+	for _, goos := range oses {
+		for _, goarch := range arches {
+			for set := 0; set < 1<<len(tags); set++ {
+				ok := expr.Eval(func(tag string) bool {
+					switch {
+					case tag == goos || tag == goarch:
+						return true
+					case tag == "unix":
+						return unixOS[goos]
+					case tag == "linux":
+						return goos == "android"
+					case tag == "solaris":
+						return goos == "illumos"
+					case tag == "darwin":
+						return goos == "ios"
+					case knownOS[tag] || knownArch[tag]:
+						return false
+					}
+					for i, t := range tags {
+						if t == tag {
+							return set&(1<<i) != 0
+						}
+					}
+					return false
+				})
+				if ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
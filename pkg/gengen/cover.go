@@ -0,0 +1,113 @@
+package gengen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Coverage is a coverage profile. It counts how many times each probe in a set of templates was reached,
+// accumulated over any number of runs, so that you can see which parts of a template the configurations
+// you use never exercise.
+type Coverage struct {
+	Probes []ProbeCount `json:"probes"`
+}
+
+// ProbeCount is a probe and the number of times execution reached it.
+type ProbeCount struct {
+	Probe
+	Count int `json:"count"`
+}
+
+// LoadCoverage reads the coverage profile at path. A missing file results in an empty profile.
+func LoadCoverage(path string) (*Coverage, error) {
+	c := new(Coverage)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s is not a coverage profile: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the coverage profile to path.
+func (c *Coverage) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// A Counter is a Monitor that counts the number of times execution reaches each probe.
+type Counter map[int]int
+
+// Probe implements the Monitor interface.
+func (c Counter) Probe(id int, _ interface{}) {
+	c[id]++
+}
+
+// End implements the Monitor interface.
+func (c Counter) End(int) {}
+
+// Add adds the counts of a run of a template with the given probes to the profile.
+func (c *Coverage) Add(probes []Probe, counts Counter) {
+	type key struct {
+		file      string
+		line, col int
+		kind      string
+	}
+	index := make(map[key]int, len(c.Probes))
+	for i, p := range c.Probes {
+		index[key{p.File, p.Line, p.Col, p.Kind}] = i
+	}
+	for i, p := range probes {
+		k := key{p.File, p.Line, p.Col, p.Kind}
+		if j, ok := index[k]; ok {
+			c.Probes[j].Count += counts[i]
+			c.Probes[j].Source = p.Source
+		} else {
+			index[k] = len(c.Probes)
+			c.Probes = append(c.Probes, ProbeCount{p, counts[i]})
+		}
+	}
+	sort.SliceStable(c.Probes, func(i, j int) bool {
+		a, b := c.Probes[i], c.Probes[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
+// Report writes a summary of the profile to w. For each template file, it lists the percentage of probes
+// that were reached, followed by the probes that never were.
+func (c *Coverage) Report(w io.Writer) {
+	for start := 0; start < len(c.Probes); {
+		file := c.Probes[start].File
+		end := start
+		var covered int
+		for ; end < len(c.Probes) && c.Probes[end].File == file; end++ {
+			if c.Probes[end].Count > 0 {
+				covered++
+			}
+		}
+		total := end - start
+		fmt.Fprintf(w, "%s: %.1f%% of %d probes executed\n", file, 100*float64(covered)/float64(total), total)
+		for _, p := range c.Probes[start:end] {
+			if p.Count == 0 {
+				fmt.Fprintf(w, "\t%d:%d: %s never executed: %s\n", p.Line, p.Col, p.Kind, p.Source)
+			}
+		}
+		start = end
+	}
+}
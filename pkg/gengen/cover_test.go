@@ -0,0 +1,75 @@
+package gengen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	tmpl, err := ParseTemplate("test", "{{if .a}}A{{else}}B{{end}}{{range .list}}{{.}}{{end}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(Counter)
+	probes := Instrument(tmpl.Template, counts)
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, map[string]interface{}{"a": true, "list": []int{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "A12" {
+		t.Errorf("Instrumenting changed the output to %q", buf.String())
+	}
+
+	c := new(Coverage)
+	c.Add(probes, counts)
+	var report bytes.Buffer
+	c.Report(&report)
+	if !strings.Contains(report.String(), "if-else never executed") ||
+		!strings.Contains(report.String(), "range-else never executed") ||
+		strings.Count(report.String(), "never executed") != 2 {
+		t.Errorf("Unexpected report:\n%s", report.String())
+	}
+
+	// a second run that takes the other branches covers everything
+	for id := range counts {
+		delete(counts, id)
+	}
+	buf.Reset()
+	if err = tmpl.Execute(&buf, map[string]interface{}{"a": false}); err != nil {
+		t.Fatal(err)
+	}
+	c.Add(probes, counts)
+	report.Reset()
+	c.Report(&report)
+	if !strings.HasPrefix(report.String(), "test: 100.0% of 5 probes executed") {
+		t.Errorf("Unexpected report:\n%s", report.String())
+	}
+}
+
+func TestTrace(t *testing.T) {
+	tmpl, err := ParseTemplate("test", "a\n{{if .a}}b\n{{.a}}\n{{end}}{{range .list}}{{.}}{{end}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	tracer := NewTracer(&buf)
+	probes := Instrument(tmpl.Template, tracer)
+	if err = tmpl.Execute(tracer, map[string]interface{}{"a": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "a\nb\nx\n" {
+		t.Errorf("Tracing changed the output to %q", buf.String())
+	}
+
+	var report bytes.Buffer
+	tracer.Report(&report, probes)
+	expected := `test:2:5: {{if .a}} is "x", then branch => output lines 2-3
+  test:3:2: {{.a}} = "x" => output lines 3-3
+test:4:15: {{range .list}} is empty, else branch => no output
+`
+	if report.String() != expected {
+		t.Errorf("Unexpected trace:\n%s", report.String())
+	}
+}
@@ -0,0 +1,121 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DataKey is the key of the dot context that data files are bound to.
+const DataKey = "Data"
+
+// LoadData reads a data file, which is decoded according to its extension:
+//   - a .csv file becomes a list of records, each a map from the names in the header row to the values
+//     of the record,
+//   - a .json file can hold any json value, like a list of objects, and its numbers are decoded like
+//     ParseConfig decodes them,
+//   - a .yaml or .yml file can hold any yaml value, and
+//   - any other file becomes a list of its lines.
+func LoadData(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		v, err = parseCSV(data)
+	case ".json":
+		v, err = decodeJSON(data)
+	case ".yaml", ".yml":
+		if err = yaml.Unmarshal(data, &v); err == nil {
+			v = normalizeYAML(v)
+		}
+	default:
+		v = parseLines(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+func parseCSV(data []byte) (interface{}, error) {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(rows) == 0 {
+		return []interface{}{}, err
+	}
+	header := rows[0]
+	records := make([]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, name := range header {
+			record[name] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func parseLines(data []byte) interface{} {
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	lines := []interface{}{}
+	if text == "" {
+		return lines
+	}
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// normalizeYAML converts the maps in a decoded yaml value that have keys other than strings to maps with
+// string keys, and its ints to int64s, like in a configuration file, so that templates can use both the
+// same way.
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeYAML(e)
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeYAML(e)
+		}
+	}
+	return v
+}
+
+// BindData adds a data value to the dot context of a template, so that the template can refer to it as
+// .Data.<name>. dot must be the json object of a configuration file.
+func BindData(dot interface{}, name string, value interface{}) error {
+	m, ok := dot.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data %q can only be added to a configuration that is a json object", name)
+	}
+	if m[DataKey] == nil {
+		m[DataKey] = make(map[string]interface{})
+	}
+	data, ok := m[DataKey].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("data %q cannot be added because the configuration already has a %s value that is not an object", name, DataKey)
+	}
+	if _, ok = data[name]; ok {
+		return fmt.Errorf("data %q is already defined", name)
+	}
+	data[name] = value
+	return nil
+}
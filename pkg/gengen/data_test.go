@@ -0,0 +1,53 @@
+package gengen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestData(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"colors.csv":  "name,value\nred,1\ngreen,2\n",
+		"colors.json": `[{"name": "red"}, {"name": "green"}]`,
+		"colors.yaml": "- name: red\n  codes: {1: a}\n- name: green\n",
+		"colors.txt":  "red\r\ngreen\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmpl, err := ParseTemplate("test", `{{range .Data.csv}}{{.name}}={{.value}} {{end}}{{range .Data.json}}{{.name}} {{end}}{{range .Data.yaml}}{{.name}}{{with .codes}}{{index . "1"}}{{end}} {{end}}{{range .Data.txt}}{{.}} {{end}}{{.a}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot, _ := ParseConfig([]byte(`{"a": "b"}`))
+	for _, ext := range []string{"csv", "json", "yaml", "txt"} {
+		value, err := LoadData(filepath.Join(dir, "colors."+ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = BindData(dot, ext, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, dot); err != nil {
+		t.Fatal(err)
+	}
+	expected := "red=1 green=2 red green reda green red green b"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	if err = BindData(dot, "csv", nil); err == nil {
+		t.Error("Expected an error binding the same name twice")
+	}
+	if err = BindData([]interface{}{}, "csv", nil); err == nil {
+		t.Error("Expected an error binding to a list")
+	}
+}
@@ -0,0 +1,178 @@
+package gengen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CheckDeclarations reports top level identifiers that are declared more than once in the go files that
+// are about to be generated, or that are also declared by another go file of the same package. Declarations
+// in files whose build constraints, or _GOOS and _GOARCH file name suffixes, keep them out of the same build
+// do not conflict.
+//
+// files maps the path of each generated file to its new content. A nil content means that the file will be
+// removed. The other files of a package are the go files in the same directory. Files that cannot be parsed
+// are skipped, since the compiler will report the problem.
+func CheckDeclarations(files map[string][]byte) error {
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var generated []*ast.File
+	pkgs := make(map[string]bool)
+	for _, path := range paths {
+		src := files[path]
+		if src == nil || !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution|parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		generated = append(generated, f)
+		pkgs[filepath.Dir(path)+" "+f.Name.Name] = true
+	}
+
+	// the other files come first, so that problems are reported in the generated files
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		others, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, other := range others {
+			if _, ok := files[other]; ok {
+				continue
+			}
+			src, err := os.ReadFile(other)
+			if err != nil {
+				continue
+			}
+			f, err := parser.ParseFile(fset, other, src, parser.SkipObjectResolution|parser.ParseComments)
+			if err != nil || !pkgs[dir+" "+f.Name.Name] {
+				continue
+			}
+			parsed = append(parsed, f)
+		}
+	}
+	parsed = append(parsed, generated...)
+
+	// an identifier can be declared again in a file that is never in the same build, like one for another
+	// operating system
+	type previous struct {
+		pos   token.Pos
+		build constraint.Expr
+	}
+	declared := make(map[string][]previous)
+	type duplicate struct {
+		declaration
+		prev token.Pos
+	}
+	var dups []duplicate
+	types := make(map[string]bool)
+	for _, f := range parsed {
+		path := fset.File(f.Pos()).Name()
+		pkg := filepath.Dir(path) + " " + f.Name.Name
+		build := buildConstraint(f, path)
+		for _, d := range declarations(f) {
+			key := pkg + " " + d.Name
+			prev := token.NoPos
+			for _, p := range declared[key] {
+				if sameBuild(p.build, build) {
+					prev = p.pos
+					break
+				}
+			}
+			if prev.IsValid() {
+				d.Name = key
+				dups = append(dups, duplicate{d, prev})
+				types[key] = true
+				continue
+			}
+			declared[key] = append(declared[key], previous{d.pos, build})
+		}
+	}
+	if dups == nil {
+		return nil
+	}
+
+	sort.Slice(dups, func(i, j int) bool {
+		return dups[i].pos < dups[j].pos
+	})
+	var problems []string
+	for _, d := range dups {
+		// the methods of a type that is declared twice are not worth mentioning
+		if i := strings.LastIndex(d.Name, "."); i >= 0 && types[d.Name[:i]] {
+			continue
+		}
+		name := d.Name[strings.LastIndex(d.Name, " ")+1:]
+		problems = append(problems, fmt.Sprintf("%s: %s is already declared at %s", fset.Position(d.pos), name, fset.Position(d.prev)))
+	}
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+type declaration struct {
+	// Name is the name of the identifier, or the receiver type and the name of a method
+	Name string
+	pos  token.Pos
+}
+
+// declarations returns the top level declarations of a file.
+func declarations(f *ast.File) (decls []declaration) {
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = receiverName(d.Recv.List[0].Type) + "." + name
+			} else if name == "init" {
+				continue
+			}
+			decls = append(decls, declaration{name, d.Name.Pos()})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					decls = append(decls, declaration{spec.Name.Name, spec.Name.Pos()})
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						if id.Name != "_" {
+							decls = append(decls, declaration{id.Name, id.Pos()})
+						}
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+// receiverName returns the name of the type of a method receiver.
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.ParenExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
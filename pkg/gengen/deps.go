@@ -0,0 +1,44 @@
+package gengen
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// Inputs returns the files that the output of a job depends on, which are the files of its template,
+// its configuration file and its data files. t is the parsed template of the job.
+func (j Job) Inputs(t *Template) []string {
+	inputs := append([]string{}, t.Files...)
+	inputs = append(inputs, j.Config)
+	var data []string
+	for _, path := range j.Data {
+		data = append(data, path)
+	}
+	sort.Strings(data)
+	return append(inputs, data...)
+}
+
+// WriteDeps writes a rule in the format of a Makefile that says that target depends on prerequisites.
+// Like the dependency files that compilers write, each prerequisite also gets a rule of its own with no
+// prerequisites, so that make does not fail when one of them is removed.
+func WriteDeps(w io.Writer, target string, prerequisites []string) error {
+	var b strings.Builder
+	b.WriteString(makeEscape(target) + ":")
+	for _, p := range prerequisites {
+		b.WriteString(" \\\n  " + makeEscape(p))
+	}
+	b.WriteString("\n")
+	for _, p := range prerequisites {
+		b.WriteString("\n" + makeEscape(p) + ":\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// makeEscape escapes the characters of a file name that are special in a Makefile rule.
+func makeEscape(s string) string {
+	s = strings.ReplaceAll(s, "$", "$$")
+	s = strings.ReplaceAll(s, "#", "\\#")
+	return strings.ReplaceAll(s, " ", "\\ ")
+}
@@ -0,0 +1,107 @@
+package gengen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteDeps(t *testing.T) {
+	var b strings.Builder
+	if err := WriteDeps(&b, "out.go", []string{"a.tmpl", "my config.json"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "out.go: \\\n  a.tmpl \\\n  my\\ config.json\n\na.tmpl:\n\nmy\\ config.json:\n"
+	if b.String() != want {
+		t.Errorf("Expected:\n%s\nGot:\n%s", want, b.String())
+	}
+
+	job := Job{Config: "c.json", Data: map[string]string{"b": "b.csv", "a": "z.csv"}}
+	inputs := job.Inputs(&Template{Files: []string{"t.tmpl", "base.tmpl"}})
+	if strings.Join(inputs, " ") != "t.tmpl base.tmpl c.json b.csv z.csv" {
+		t.Errorf("Unexpected inputs %v", inputs)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.json")
+	out := filepath.Join(dir, "out.go")
+	write := func(path, text string) {
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(in, `{"a": 1}`)
+	write(out, "package a")
+
+	c := Cache{Dir: filepath.Join(dir, "cache")}
+	key, err := c.Key([]string{in}, "setting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Fresh(key, out, "") {
+		t.Error("Expected a missing entry to not be fresh")
+	}
+	if err = c.Save(key, out, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Fresh(key, out, "") {
+		t.Error("Expected a saved entry to be fresh")
+	}
+	write(out, "package b")
+	if c.Fresh(key, out, "") {
+		t.Error("Expected a changed output to not be fresh")
+	}
+
+	// the sections of the shared file that the output uses are saved with it
+	shared := filepath.Join(dir, "gengen_shared.go")
+	update := func(user, text string) {
+		f, err := LoadSharedFile(shared)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = f.Update(user, []byte("package b"), []Section{{Name: user, Text: text}}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := f.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		write(shared, string(data))
+	}
+	update("out.go", "func a() {}")
+	if err = c.Save(key, out, shared); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Fresh(key, out, shared) {
+		t.Error("Expected a saved entry with a shared file to be fresh")
+	}
+	update("other.go", "func b() {}")
+	if !c.Fresh(key, out, shared) {
+		t.Error("Expected the sections of other files to not matter")
+	}
+	update("out.go", "func a() { println() }")
+	if c.Fresh(key, out, shared) {
+		t.Error("Expected a changed section to not be fresh")
+	}
+	if err = c.Save(key, out, shared); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(shared)
+	if c.Fresh(key, out, shared) {
+		t.Error("Expected a removed shared file to not be fresh")
+	}
+
+	if k, _ := c.Key([]string{in}, "other"); k == key {
+		t.Error("Expected the settings to change the key")
+	}
+	write(in, `{"a": 2}`)
+	if k, _ := c.Key([]string{in}, "setting"); k == key {
+		t.Error("Expected the content of the inputs to change the key")
+	}
+	if _, err = c.Key([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Error("Expected an error for a missing input")
+	}
+}
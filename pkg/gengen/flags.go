@@ -0,0 +1,55 @@
+package gengen
+
+import (
+	"flag"
+	"strings"
+)
+
+// Flags are the options of the gengen command that runs a template, or the jobs of a manifest. They are
+// defined here so that the go:generate lines that run gengen are read with the same options the command has.
+type Flags struct {
+	Config     string
+	Output     string
+	Manifest   string
+	Cover      string
+	Trace      bool
+	Delims     string
+	Data       []string
+	Deps       string
+	Cache      string
+	Prune      bool
+	Limits     Limits
+	Restricted bool
+	Shared     string
+}
+
+// Define defines the options on fs, which store their values in f.
+func (f *Flags) Define(fs *flag.FlagSet) {
+	fs.StringVar(&f.Config, "c", "", "A required config file that will be used to provide the *dot* context to the template.")
+	fs.StringVar(&f.Output, "o", "", "Output file. If not specified, output will be sent to stdout.")
+	fs.StringVar(&f.Manifest, "m", "", "A manifest file that lists the jobs to run, instead of using the -c and -o options and a template file.")
+	fs.StringVar(&f.Cover, "cover", "", "Coverage profile. Counts of the template actions and branches executed are added to this file.")
+	fs.BoolVar(&f.Trace, "trace", false, "Log each template action and branch executed, with the output lines it produced, to stderr.")
+	fs.StringVar(&f.Delims, "delims", "", "The left and right delimiters of the template, separated by a space, like \"[[ ]]\". Front matter in the template overrides this.")
+	fs.Var((*ListFlag)(&f.Data), "data", "A data file, given as name=path, whose content is available to the template as .Data.name. Repeat for more files.")
+	fs.StringVar(&f.Deps, "deps", "", "A file that the dependencies of the output files are written to, as Makefile rules. Requires the -o or -m option.")
+	fs.StringVar(&f.Cache, "cache", "", "A directory that remembers the inputs of the output files, so that outputs whose inputs have not changed are not generated again.")
+	fs.BoolVar(&f.Prune, "prune", false, "Remove the files that the manifest generated before, but no longer does. Without a manifest, record the output file in the current directory, and remove the files recorded there that are not the output of a go:generate line.")
+	fs.DurationVar(&f.Limits.Timeout, "timeout", 0, "The longest time a template can take to execute, like \"10s\". Zero means no limit.")
+	fs.Int64Var(&f.Limits.MaxOutput, "max-output", 0, "The largest number of bytes a template can produce. Zero means no limit.")
+	fs.IntVar(&f.Limits.MaxDepth, "max-depth", 0, "The deepest that template calls can be nested. Zero means no limit.")
+	fs.BoolVar(&f.Restricted, "restricted", false, "Only let templates extend templates of the library, or templates in their own directory or below it.")
+	fs.StringVar(&f.Shared, "shared", "gengen_shared.go", "The file in the directory of a go output file that sections produced with the shared function are collected in. If empty, they are put in the output file.")
+}
+
+// A ListFlag is the value of an option that can be repeated, which collects the values given in order.
+type ListFlag []string
+
+func (l *ListFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *ListFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
@@ -0,0 +1,137 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FrontMatter holds the settings that a template declares in a comment at its very start, like this:
+//
+//	{{/*gengen {"delims": "[[ ]]"} */}}
+//
+// The comment holds a json object, and is written with the delimiters of the template, so the example above
+// could also start with "[[" and end with "]]".
+type FrontMatter struct {
+	// Delims are the left and right delimiters of the actions of the template, separated by a space.
+	Delims string `json:"delims,omitempty"`
+	// Params describe the configuration values that the template uses. See ApplyParams.
+	Params map[string]Param `json:"params,omitempty"`
+	// Requires is the oldest version of gengen that can execute the template, like "0.3.0".
+	Requires string `json:"requires,omitempty"`
+	// Features are the features of gengen that the template needs, from those listed in Features.
+	Features []string `json:"features,omitempty"`
+}
+
+var frontMatterRE = regexp.MustCompile(`^(\S*?)(- )?/\*gengen\b((?s).*?)\*/( -)?`)
+
+// readFrontMatter returns the front matter of a template, and the text of the template with the front matter
+// replaced by an empty comment that takes up the same number of lines. delims are the delimiters to use
+// if the front matter does not set them. If the template has no front matter, text is returned as it is.
+func readFrontMatter(text string, delims string) (fm FrontMatter, _ string, err error) {
+	m := frontMatterRE.FindStringSubmatch(text)
+	if m != nil && strings.TrimSpace(m[3]) != "" {
+		// the requirements are checked first, so that a template for a newer version of gengen is refused
+		// because of its version, rather than because of the settings that are new in that version
+		var req FrontMatter
+		if err = json.Unmarshal([]byte(m[3]), &req); err == nil {
+			if err = req.checkRequirements(); err != nil {
+				return fm, "", err
+			}
+		}
+		dec := json.NewDecoder(strings.NewReader(m[3]))
+		dec.DisallowUnknownFields()
+		dec.UseNumber()
+		if err = dec.Decode(&fm); err != nil {
+			return fm, "", fmt.Errorf("front matter: %w", err)
+		}
+		for key, p := range fm.Params {
+			if err = p.check(); err != nil {
+				return fm, "", fmt.Errorf("front matter: param %q: %w", key, err)
+			}
+			p.Default = numbers(p.Default)
+			fm.Params[key] = p
+		}
+	}
+	if fm.Delims == "" {
+		fm.Delims = delims
+	}
+	left, right, err := fm.delims()
+	if err != nil || m == nil {
+		return fm, text, err
+	}
+
+	// the front matter is written with the delimiters it sets, or the standard ones
+	rest := text[len(m[0]):]
+	if m[1] == left && strings.HasPrefix(rest, right) {
+		rest = rest[len(right):]
+	} else if m[1] == "{{" && strings.HasPrefix(rest, "}}") {
+		rest = rest[2:]
+	} else {
+		return fm, "", fmt.Errorf("front matter must be a comment that starts with %q or \"{{\"", left)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(left + m[2] + "/*")
+	buf.WriteString(strings.Repeat("\n", strings.Count(m[0], "\n")))
+	buf.WriteString("*/" + m[4] + right)
+	return fm, buf.String() + rest, nil
+}
+
+// delims returns the left and right delimiters of the template.
+func (fm FrontMatter) delims() (left, right string, err error) {
+	if fm.Delims == "" {
+		return "{{", "}}", nil
+	}
+	d := strings.Fields(fm.Delims)
+	if len(d) != 2 {
+		return "", "", fmt.Errorf("delimiters %q must be a left and a right delimiter separated by a space", fm.Delims)
+	}
+	return d[0], d[1], nil
+}
+
+// checkRequirements returns an error if this version of gengen does not meet the requirements of the template.
+func (fm FrontMatter) checkRequirements() error {
+	if fm.Requires != "" {
+		want, err := parseVersion(fm.Requires)
+		if err != nil {
+			return fmt.Errorf("front matter: requires: %w", err)
+		}
+		have, _ := parseVersion(Version)
+		for i := range want {
+			if have[i] != want[i] {
+				if have[i] < want[i] {
+					return fmt.Errorf("the template requires gengen %s or later, but this is gengen %s; upgrade gengen to use it", fm.Requires, Version)
+				}
+				break
+			}
+		}
+	}
+	var missing []string
+	for _, f := range fm.Features {
+		if _, ok := Features[f]; !ok {
+			missing = append(missing, strconv.Quote(f))
+		}
+	}
+	if missing != nil {
+		return fmt.Errorf("the template requires features that gengen %s does not have: %s; upgrade gengen, or run gengen version to see the features it has", Version, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// parseVersion parses a version like "1.2.3", or "v1.2", into its major, minor and patch numbers.
+func parseVersion(v string) (n [3]int, err error) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) > 3 {
+		return n, fmt.Errorf("bad version %q", v)
+	}
+	for i, p := range parts {
+		if n[i], err = strconv.Atoi(p); err != nil || n[i] < 0 {
+			return n, fmt.Errorf("bad version %q", v)
+		}
+	}
+	return n, nil
+}
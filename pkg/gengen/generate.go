@@ -0,0 +1,219 @@
+package gengen
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A generateLine is a go:generate line that runs gengen.
+type generateLine struct {
+	// job holds the files of the line, resolved relative to its directory. Any of them can be empty.
+	job Job
+	// manifest is the manifest the line runs, if it has the -m option.
+	manifest string
+}
+
+// the options of gengen that take a value, which are written as "-x value" or "-x=value"
+var valueOptions = func() map[string]bool {
+	fs := flag.NewFlagSet("gengen", flag.ContinueOnError)
+	new(Flags).Define(fs)
+	options := make(map[string]bool)
+	fs.VisitAll(func(f *flag.Flag) {
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+			options[f.Name] = true
+		}
+	})
+	return options
+}()
+
+// GenerateJobs returns the jobs of the go:generate lines in the go files of dir that run gengen, including the
+// jobs of the manifests they run. Lines that do not have a config file, a template file and an output file
+// are skipped.
+func GenerateJobs(dir string) ([]Job, error) {
+	lines, err := generateLines(dir)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	for _, line := range lines {
+		if line.manifest != "" {
+			m, err := LoadManifest(line.manifest)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, m.Jobs...)
+		} else if line.job.Template != "" && line.job.Config != "" && line.job.Output != "" {
+			jobs = append(jobs, line.job)
+		}
+	}
+	return jobs, nil
+}
+
+// GenerateOutputs returns the output files of the go:generate lines in the go files of dir that run gengen
+// with the -o option.
+func GenerateOutputs(dir string) ([]string, error) {
+	lines, err := generateLines(dir)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []string
+	for _, line := range lines {
+		if line.job.Output != "" {
+			outputs = append(outputs, line.job.Output)
+		}
+	}
+	return outputs, nil
+}
+
+// generateLines returns the go:generate lines in the go files of dir that run gengen.
+func generateLines(dir string) ([]generateLine, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var lines []generateLine
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, text := range strings.Split(string(data), "\n") {
+			if !strings.HasPrefix(text, "//go:generate ") {
+				continue
+			}
+			args := gengenArgs(splitGenerate(strings.TrimSpace(text[len("//go:generate "):])))
+			if args == nil {
+				continue
+			}
+			line, err := parseGenerateLine(args, file)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// parseGenerateLine reads the options of gengen in the arguments of a go:generate line of file.
+func parseGenerateLine(args []string, file string) (line generateLine, err error) {
+	dir := filepath.Dir(file)
+	expand := func(s string) string {
+		// go generate sets $GOFILE, and the rest comes from the environment
+		return os.Expand(s, func(name string) string {
+			if name == "GOFILE" {
+				return filepath.Base(file)
+			}
+			return os.Getenv(name)
+		})
+	}
+	values := make(map[string][]string)
+	var template string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			template = expand(arg)
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		value := ""
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value = name[:j], name[j+1:]
+		} else if valueOptions[name] && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		values[name] = append(values[name], expand(value))
+	}
+
+	last := func(name string) string {
+		if v := values[name]; len(v) > 0 {
+			return v[len(v)-1]
+		}
+		return ""
+	}
+	job := &line.job
+	if m := last("m"); m != "" {
+		line.manifest, err = resolve(m, dir)
+		return
+	}
+	if template != "" {
+		if job.Template, err = ResolveTemplate(template, dir); err != nil {
+			return
+		}
+	}
+	if c := last("c"); c != "" {
+		if job.Config, err = resolve(c, dir); err != nil {
+			return
+		}
+	}
+	if o := last("o"); o != "" {
+		if job.Output, err = resolve(o, dir); err != nil {
+			return
+		}
+	}
+	job.Delims = last("delims")
+	for _, d := range values["data"] {
+		i := strings.Index(d, "=")
+		if i <= 0 {
+			return line, fmt.Errorf("data file %q must be given as name=path", d)
+		}
+		if job.Data == nil {
+			job.Data = make(map[string]string)
+		}
+		if job.Data[d[:i]], err = resolve(d[i+1:], dir); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// gengenArgs returns the arguments of a go:generate command that runs gengen, either installed or with go run,
+// or nil if the command does not run gengen.
+func gengenArgs(words []string) []string {
+	if len(words) > 0 && filepath.Base(words[0]) == "gengen" {
+		return words[1:]
+	}
+	if len(words) > 2 && words[0] == "go" && words[1] == "run" && strings.HasSuffix(words[2], "/gengen") {
+		return words[3:]
+	}
+	return nil
+}
+
+// splitGenerate splits the command of a go:generate line into words the way go generate does, which is at
+// spaces, except inside double quoted strings.
+func splitGenerate(line string) []string {
+	var words []string
+	line = strings.TrimSpace(line)
+	for line != "" {
+		var word string
+		if line[0] == '"' {
+			end := 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(line) {
+				end++
+			}
+			word = line[:end]
+			if s, err := strconv.Unquote(word); err == nil {
+				word = s
+			}
+			line = line[end:]
+		} else if i := strings.IndexAny(line, " \t"); i >= 0 {
+			word, line = line[:i], line[i:]
+		} else {
+			word, line = line, ""
+		}
+		words = append(words, word)
+		line = strings.TrimLeft(line, " \t")
+	}
+	return words
+}
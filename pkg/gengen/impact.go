@@ -0,0 +1,225 @@
+package gengen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Run executes the job and returns its output, with the output of calls to the shared function in place,
+// after running it through the processors of the job. Nothing is written.
+func (j Job) Run() ([]byte, error) {
+	out, processors, err := j.run(nil)
+	if err != nil {
+		return nil, err
+	}
+	return Process(j.Output, out, processors)
+}
+
+// run executes the job, with rebase changing the paths of its template and the templates it extends, if it
+// is not nil. It returns the output along with the processors of the job, which are not run.
+func (j Job) run(rebase func(string) string) ([]byte, []Processor, error) {
+	dot, err := LoadConfig(j.Config)
+	if err != nil {
+		return nil, nil, err
+	}
+	path := j.Template
+	if rebase != nil {
+		path = rebase(path)
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	job := j
+	job.Template = path
+	p, err := job.Prepare(dot, string(text), Options{Rebase: rebase})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = CheckLocks(j.Inputs(p.Parsed)...); err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if _, err = p.Execute(&buf, false); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), p.Processors, nil
+}
+
+// An Impact is how a change to templates changes one output file.
+type Impact struct {
+	Output string
+	// Old and New are the output of the old and new versions of the templates.
+	Old, New []byte
+	// OldErr and NewErr are the errors that stopped the old and new versions from producing the output.
+	OldErr, NewErr error
+	// Added and Removed are the exported declarations of a go output that the new version adds and removes,
+	// as described by API.
+	Added, Removed []string
+}
+
+// Changed returns true if the new version of the templates produces a different output than the old one.
+func (i Impact) Changed() bool {
+	return !bytes.Equal(i.Old, i.New) || (i.OldErr == nil) != (i.NewErr == nil)
+}
+
+// CompareTemplates runs the jobs with two versions of their templates, which are in the directories oldDir
+// and newDir, and returns the impact of the change on each output file, in the order the outputs first
+// appear in the jobs. The paths of the jobs refer to the new version. For the old version, a template in
+// newDir, or that a template extends from newDir, is read from the same place in oldDir instead.
+// The outputs of jobs with the same output file are combined as they are by a manifest.
+func CompareTemplates(jobs []Job, oldDir, newDir string) ([]Impact, error) {
+	var err error
+	if oldDir, err = filepath.Abs(oldDir); err != nil {
+		return nil, err
+	}
+	if newDir, err = filepath.Abs(newDir); err != nil {
+		return nil, err
+	}
+	rebase := func(path string) string {
+		rel, err := filepath.Rel(newDir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return path
+		}
+		return filepath.Join(oldDir, rel)
+	}
+
+	outputs, byOutput := (&Manifest{Jobs: jobs}).Outputs()
+	var impacts []Impact
+	for _, output := range outputs {
+		i := Impact{Output: output}
+		i.Old, i.OldErr = runOutput(output, byOutput[output], rebase)
+		i.New, i.NewErr = runOutput(output, byOutput[output], nil)
+		if i.OldErr == nil && i.NewErr == nil && strings.HasSuffix(output, ".go") {
+			i.Added, i.Removed = compareAPI(API(i.Old), API(i.New))
+		}
+		impacts = append(impacts, i)
+	}
+	return impacts, nil
+}
+
+// runOutput runs the jobs that produce output, combines their output, and runs it through their processors.
+func runOutput(output string, jobs []Job, rebase func(string) string) ([]byte, error) {
+	var parts []Part
+	var lists [][]Processor
+	for n, job := range jobs {
+		out, processors, err := job.run(rebase)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, processors)
+		name := job.Template
+		if len(jobs) > 1 {
+			name = fmt.Sprintf("part %d (%s)", n+1, job)
+		}
+		parts = append(parts, Part{Name: name, Out: out})
+	}
+	return Assemble(output, parts, OutputProcessors(lists...))
+}
+
+// API returns the exported declarations of a go file, sorted, each described on one line like
+// "func (*StringMap) Get(string) string", "type StringMap" or "var Zero". Methods are included if their
+// receiver type is exported, and so are the methods of exported interfaces. The names of params are left out,
+// so that only changes that matter to callers show. API returns nil if src is not valid go.
+func API(src []byte) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var api []string
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				api = append(api, "func "+d.Name.Name+signature(d.Type))
+			} else if ast.IsExported(receiverName(d.Recv.List[0].Type)) {
+				api = append(api, "func ("+types.ExprString(d.Recv.List[0].Type)+") "+d.Name.Name+signature(d.Type))
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if !spec.Name.IsExported() {
+						continue
+					}
+					api = append(api, d.Tok.String()+" "+spec.Name.Name)
+					if it, ok := spec.Type.(*ast.InterfaceType); ok {
+						for _, m := range it.Methods.List {
+							ft, ok := m.Type.(*ast.FuncType)
+							if !ok || len(m.Names) == 0 || !m.Names[0].IsExported() {
+								continue // an embedded interface
+							}
+							api = append(api, "func ("+spec.Name.Name+") "+m.Names[0].Name+signature(ft))
+						}
+					}
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						if id.IsExported() {
+							api = append(api, d.Tok.String()+" "+id.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(api)
+	return api
+}
+
+// signature describes the params and results of a function without their names.
+func signature(ft *ast.FuncType) string {
+	s := "(" + fieldTypes(ft.Params) + ")"
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return s
+	}
+	results := fieldTypes(ft.Results)
+	if len(ft.Results.List) == 1 && len(ft.Results.List[0].Names) <= 1 {
+		return s + " " + results
+	}
+	return s + " (" + results + ")"
+}
+
+// fieldTypes lists the types of a list of fields, once for each name.
+func fieldTypes(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var list []string
+	for _, f := range fields.List {
+		t := types.ExprString(f.Type)
+		for n := 0; n < len(f.Names) || n == 0; n++ {
+			list = append(list, t)
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+// compareAPI returns the declarations that are in new but not old, and those that are in old but not new.
+func compareAPI(old, new []string) (added, removed []string) {
+	in := func(list []string, s string) bool {
+		i := sort.SearchStrings(list, s)
+		return i < len(list) && list[i] == s
+	}
+	for _, s := range new {
+		if !in(old, s) {
+			added = append(added, s)
+		}
+	}
+	for _, s := range old {
+		if !in(new, s) {
+			removed = append(removed, s)
+		}
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package gengen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	if d := Diff("a", "b", []byte("x\n"), []byte("x\n")); d != "" {
+		t.Errorf("Expected no diff, got %q", d)
+	}
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	new := strings.Replace(strings.Replace(old, "2\n", "two\n", 1), "15\n", "15\n15.5\n", 1)
+	want := `--- a
++++ b
+@@ -1,5 +1,5 @@
+ 1
+-2
++two
+ 3
+ 4
+ 5
+@@ -13,4 +13,5 @@
+ 13
+ 14
+ 15
++15.5
+ 16
+`
+	if d := Diff("a", "b", []byte(old), []byte(new)); d != want {
+		t.Errorf("Unexpected diff:\n%s", d)
+	}
+	if d := Diff("a", "b", []byte("x"), []byte("y")); d != "--- a\n+++ b\n@@ -1 +1 @@\n-x\n\\ No newline at end of file\n+y\n\\ No newline at end of file\n" {
+		t.Errorf("Unexpected diff:\n%s", d)
+	}
+}
+
+func TestAPI(t *testing.T) {
+	src := `package a
+
+type Map struct{}
+type hidden struct{}
+type Getter interface {
+	Get(key string) string
+	io.Reader
+}
+
+var Zero, one = 0, 1
+
+func New() *Map { return nil }
+func (m *Map) Get(key, def string) (v string, ok bool) { return }
+func (m *Map) set() {}
+func (h hidden) Get() {}
+
+type SliceMap[K comparable, V any] struct{}
+type sliceMap[K comparable, V any] struct{}
+
+func (m *SliceMap[K, V]) Len() int { return 0 }
+func (m *sliceMap[K, V]) Len() int { return 0 }
+`
+	want := []string{
+		"func (*Map) Get(string, string) (string, bool)",
+		"func (*SliceMap[K, V]) Len() int",
+		"func (Getter) Get(string) string",
+		"func New() *Map",
+		"type Getter",
+		"type Map",
+		"type SliceMap",
+		"var Zero",
+	}
+	if api := API([]byte(src)); !reflect.DeepEqual(api, want) {
+		t.Errorf("Expected %q, got %q", want, api)
+	}
+}
+
+func TestCompareTemplates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("old/base.tmpl", "package {{.pkg}}\n\nfunc Get() int { return 1 }\n{{block \"more\" .}}{{end}}")
+	write("old/a.tmpl", "{{extends \"base.tmpl\"}}")
+	write("new/base.tmpl", "package {{.pkg}}\n\nfunc Get() int { return 1 }\n\nfunc Put(int) {}\n{{block \"more\" .}}{{end}}")
+	write("new/a.tmpl", "{{extends \"base.tmpl\"}}")
+	write("new/b.tmpl", "plain {{.pkg}}\n")
+	write("out/a.json", `{"pkg": "a"}`)
+	write("out/gen.go", "package a\n\n//go:generate gengen -c a.json -o a.go ../new/a.tmpl\n//go:generate gengen -c a.json -o b.txt ../new/b.tmpl\n")
+
+	jobs, err := GenerateJobs(filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].Template != filepath.Join(dir, "new", "a.tmpl") || jobs[1].Config != filepath.Join(dir, "out", "a.json") {
+		t.Fatalf("Unexpected jobs %v", jobs)
+	}
+
+	impacts, err := CompareTemplates(jobs, filepath.Join(dir, "old"), filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(impacts) != 2 {
+		t.Fatalf("Expected 2 impacts, got %v", impacts)
+	}
+	a := impacts[0]
+	if a.OldErr != nil || a.NewErr != nil || !a.Changed() {
+		t.Errorf("Expected a.go to change, got %v %v", a.OldErr, a.NewErr)
+	}
+	if !reflect.DeepEqual(a.Added, []string{"func Put(int)"}) || a.Removed != nil {
+		t.Errorf("Unexpected API changes %v %v", a.Added, a.Removed)
+	}
+	if b := impacts[1]; b.OldErr == nil || b.NewErr != nil || string(b.New) != "plain a\n" {
+		t.Errorf("Expected b.txt to be new, got %q %v %v", b.New, b.OldErr, b.NewErr)
+	}
+}
@@ -0,0 +1,318 @@
+package gengen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Limits restrict the resources that executing a template can use, so that a template from an untrusted
+// source cannot run forever or fill the disk. A zero value means no limit.
+type Limits struct {
+	// Timeout is how long the template can take to execute.
+	Timeout time.Duration
+	// MaxOutput is the largest number of bytes the template can write, including the output of calls to the
+	// shared function.
+	MaxOutput int64
+	// MaxDepth is the largest number of template calls, made with template actions or the shared function,
+	// that can be in progress at once, which stops templates that call themselves without end.
+	MaxDepth int
+}
+
+// Names of the functions that count the depth of template calls, and that stop an execution that took too long.
+const (
+	enterFunc = "gengenEnter"
+	leaveFunc = "gengenLeave"
+	checkFunc = "gengenCheck"
+)
+
+// errCancelled stops an execution that took longer than its timeout.
+var errCancelled = errors.New("the execution was cancelled because it took too long")
+
+// checkSandboxedRef returns an error if a restricted template cannot extend the template that ref names.
+// It can extend the templates of the Library, and the templates in its own directory or below it, named
+// with a relative path without environment variables. Since a relative path can also name a template in a
+// module, checkSandboxedPath checks where the path leads once it is resolved.
+func checkSandboxedRef(ref string) error {
+	if strings.HasPrefix(ref, libPrefix) {
+		return nil
+	}
+	clean := filepath.Clean(filepath.FromSlash(ref))
+	if strings.Contains(ref, "$") || filepath.IsAbs(clean) || filepath.VolumeName(clean) != "" || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return errSandboxedRef(ref)
+	}
+	return nil
+}
+
+// checkSandboxedPath returns an error if path, which a restricted template in dir extends with ref, is not
+// in dir or below it, after following symlinks.
+func checkSandboxedPath(ref, path, dir string) error {
+	if strings.HasPrefix(ref, libPrefix) {
+		return nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errSandboxedRef(ref)
+	}
+	return nil
+}
+
+func errSandboxedRef(ref string) error {
+	return fmt.Errorf("a restricted template can only extend templates of the Library or templates in its own directory, not %q", ref)
+}
+
+// limit sets the limits of executing the template.
+func (t *Template) limit(l Limits) {
+	t.limits = l
+	if l.MaxDepth <= 0 && l.Timeout <= 0 {
+		return
+	}
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil && tmpl.Tree.Root != nil {
+			addLimitCalls(tmpl.Tree, tmpl.Tree.Root, l.Timeout > 0)
+		}
+	}
+	t.Funcs(template.FuncMap{
+		enterFunc: func(loc string) (string, error) {
+			if err := t.checkCancelled(); err != nil {
+				return "", err
+			}
+			if t.depth++; l.MaxDepth > 0 && t.depth > l.MaxDepth {
+				t.depthErr = fmt.Errorf("%s: template calls are nested more than %d deep", loc, l.MaxDepth)
+				return "", t.depthErr
+			}
+			return "", nil
+		},
+		leaveFunc: func() string {
+			t.depth--
+			return ""
+		},
+		checkFunc: func() (string, error) {
+			return "", t.checkCancelled()
+		},
+	})
+}
+
+// checkCancelled returns errCancelled if the execution in progress took longer than its timeout.
+func (t *Template) checkCancelled() error {
+	if atomic.LoadInt32(&t.cancelled) != 0 {
+		return errCancelled
+	}
+	return nil
+}
+
+// addLimitCalls puts a call of enterFunc before each template action in l, with the location of the action in
+// tree, and a call of leaveFunc after it. If check is true, it also puts a call of checkFunc at the start of
+// each range iteration, so that a loop that writes nothing still stops when the execution is cancelled.
+func addLimitCalls(tree *parse.Tree, l *parse.ListNode, check bool) {
+	if l == nil {
+		return
+	}
+	nodes := make([]parse.Node, 0, len(l.Nodes))
+	for _, n := range l.Nodes {
+		switch n := n.(type) {
+		case *parse.TemplateNode:
+			loc, _ := tree.ErrorContext(n)
+			nodes = append(nodes, funcCall(enterFunc+" "+strconv.Quote(loc)), n, funcCall(leaveFunc))
+			continue
+		case *parse.IfNode:
+			addLimitCalls(tree, n.List, check)
+			addLimitCalls(tree, n.ElseList, check)
+		case *parse.RangeNode:
+			addLimitCalls(tree, n.List, check)
+			addLimitCalls(tree, n.ElseList, check)
+			if check && n.List != nil {
+				n.List.Nodes = append([]parse.Node{funcCall(checkFunc)}, n.List.Nodes...)
+			}
+		case *parse.WithNode:
+			addLimitCalls(tree, n.List, check)
+			addLimitCalls(tree, n.ElseList, check)
+		}
+		nodes = append(nodes, n)
+	}
+	l.Nodes = nodes
+}
+
+// isLimitCall returns true if n is an action that addLimitCalls put in a template.
+func isLimitCall(n *parse.ActionNode) bool {
+	if len(n.Pipe.Cmds) != 1 || len(n.Pipe.Cmds[0].Args) == 0 {
+		return false
+	}
+	id, ok := n.Pipe.Cmds[0].Args[0].(*parse.IdentifierNode)
+	return ok && (id.Ident == enterFunc || id.Ident == leaveFunc || id.Ident == checkFunc)
+}
+
+// funcCall returns an action that calls a function, given with its arguments.
+func funcCall(call string) *parse.ActionNode {
+	f := strings.Fields(call)[0]
+	trees, err := parse.Parse("limit", "{{"+call+"}}", "", "", map[string]interface{}{f: fmt.Sprint})
+	if err != nil {
+		panic(err) // the call is our own text, so this would be a bug
+	}
+	return trees["limit"].Root.Nodes[0].(*parse.ActionNode)
+}
+
+// Execute executes the template like the Execute method of text/template does, within the Limits option
+// the template was parsed with. If the template takes longer than its timeout, Execute returns an error once
+// the template stops at its next write, range iteration, template call or probe, or after a short wait if it
+// is stuck in a function. Either way, it writes nothing more to w and tells the monitors of its Instrument
+// method nothing more. The next execution waits for it to stop.
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	l := t.limits
+	if l.Timeout <= 0 && l.MaxOutput <= 0 && l.MaxDepth <= 0 {
+		return t.Template.Execute(w, data)
+	}
+	if t.running != nil {
+		// an execution that took too long shares the state of the template, so it must stop first
+		select {
+		case <-t.running:
+		case <-time.After(l.Timeout):
+			return fmt.Errorf("%s: an earlier execution of the template that took too long has not stopped", t.Name())
+		}
+	}
+	atomic.StoreInt32(&t.cancelled, 0)
+	t.depth, t.depthErr = 0, nil
+	lw := &limitWriter{w: w, count: &outputCount{max: l.MaxOutput}}
+	t.out = lw
+	execute := func() error {
+		err := t.Template.Execute(lw, data)
+		if err != nil && t.depthErr != nil {
+			// the error of text/template would point at the call of enterFunc, rather than the template call
+			return t.depthErr
+		}
+		return err
+	}
+	if l.Timeout <= 0 {
+		return execute()
+	}
+
+	done := make(chan error, 1)
+	running := make(chan struct{})
+	t.running = running
+	go func() {
+		defer close(running)
+		done <- execute()
+	}()
+	timer := time.NewTimer(l.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		err := fmt.Errorf("%s: the template took longer than %s to execute", t.Name(), l.Timeout)
+		t.monitorMu.Lock()
+		atomic.StoreInt32(&t.cancelled, 1)
+		t.monitorMu.Unlock()
+		lw.stop(err)
+		// the execution stops at its next range iteration, template call or probe, unless it is stuck in a
+		// function; either way, it tells the monitors and w nothing more
+		select {
+		case <-running:
+		case <-time.After(stopWait):
+		}
+		return err
+	}
+}
+
+// stopWait is how long Execute waits for an execution that took too long to stop.
+const stopWait = 100 * time.Millisecond
+
+// Instrument inserts probes into the template like the Instrument function does. Unlike with that function,
+// the monitors are told nothing more once an execution takes longer than its timeout, so that they can be
+// read as soon as Execute returns.
+func (t *Template) Instrument(monitors ...Monitor) []Probe {
+	guarded := make([]Monitor, len(monitors))
+	for i, m := range monitors {
+		guarded[i] = cancellable{t, m}
+	}
+	return Instrument(t.Template, guarded...)
+}
+
+// A cancellable passes the probes reached on to a Monitor while the execution of its template is not cancelled.
+type cancellable struct {
+	t *Template
+	m Monitor
+}
+
+func (c cancellable) Probe(id int, val interface{}) {
+	c.t.monitorMu.Lock()
+	defer c.t.monitorMu.Unlock()
+	if c.t.checkCancelled() == nil {
+		c.m.Probe(id, val)
+	}
+}
+
+func (c cancellable) End(id int) {
+	c.t.monitorMu.Lock()
+	defer c.t.monitorMu.Unlock()
+	if c.t.checkCancelled() == nil {
+		c.m.End(id)
+	}
+}
+
+// A limitWriter passes writes on to w until the bytes written with it, and with the writers made from it
+// with to, are more than the limit of its count, or it is stopped.
+type limitWriter struct {
+	w     io.Writer
+	count *outputCount
+}
+
+// An outputCount is the number of bytes that the writers of an output have written, and the limit on it.
+type outputCount struct {
+	mu      sync.Mutex
+	max     int64
+	written int64
+	err     error
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	c := lw.count
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, c.err
+	}
+	if c.max > 0 && c.written+int64(len(p)) > c.max {
+		c.err = fmt.Errorf("the output is larger than the limit of %d bytes", c.max)
+		return 0, c.err
+	}
+	c.written += int64(len(p))
+	return lw.w.Write(p)
+}
+
+// to returns a writer to w that shares the count of lw.
+func (lw *limitWriter) to(w io.Writer) *limitWriter {
+	return &limitWriter{w: w, count: lw.count}
+}
+
+// refund takes n bytes, which are about to be written again, off the count.
+func (lw *limitWriter) refund(n int) {
+	lw.count.mu.Lock()
+	lw.count.written -= int64(n)
+	lw.count.mu.Unlock()
+}
+
+// stop makes the writes that follow fail with err.
+func (lw *limitWriter) stop(err error) {
+	lw.count.mu.Lock()
+	lw.count.err = err
+	lw.count.mu.Unlock()
+}
@@ -0,0 +1,135 @@
+package gengen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		text   string
+		limits Limits
+		err    string
+	}{
+		{`{{define "r"}}x{{if .}}{{template "r" .}}{{end}}{{end}}{{template "r" .}}`, Limits{MaxDepth: 5}, "test:1:34: template calls are nested more than 5 deep"},
+		{`{{define "r"}}x{{shared "r" .}}{{end}}{{template "r" .}}`, Limits{MaxDepth: 5}, `shared "r": template calls are nested more than 5 deep`},
+		{`{{range .}}0123456789{{end}}`, Limits{MaxOutput: 95}, "larger than the limit of 95 bytes"},
+		{`{{range .}}{{range $}}{{range $}}{{end}}{{end}}{{end}}`, Limits{Timeout: 50 * time.Millisecond}, "took longer than 50ms"},
+	}
+	data := make([]int, 1000)
+	for _, test := range tests {
+		tmpl, err := ParseTemplateOptions("test", test.text, Options{Limits: test.limits})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = tmpl.Execute(&bytes.Buffer{}, data); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Expected an error with %q executing %q, got %v", test.err, test.text, err)
+		}
+		if tmpl.running != nil {
+			// the execution that took too long stops too
+			select {
+			case <-tmpl.running:
+			case <-time.After(5 * time.Second):
+				t.Errorf("Expected the execution of %q to stop", test.text)
+			}
+		}
+	}
+
+	// within the limits, the output is the same
+	tmpl, err := ParseTemplateOptions("test", `{{define "a"}}{{template "b" .}}{{end}}{{define "b"}}b{{end}}{{template "a" .}}{{template "a" .}}`, Options{Limits: Limits{MaxDepth: 2, MaxOutput: 2, Timeout: time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, nil); err != nil || buf.String() != "bb" {
+		t.Errorf("Unexpected output %q %v", buf.String(), err)
+	}
+
+	// a trace can be reported as soon as an execution that took too long returns
+	text := `{{range .}}{{range $}}{{range $}}{{.}}{{end}}{{end}}{{end}}`
+	if tmpl, err = ParseTemplateOptions("test", text, Options{Limits: Limits{Timeout: 50 * time.Millisecond}}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	tracer := NewTracer(&buf)
+	probes := tmpl.Instrument(tracer)
+	if err = tmpl.Execute(tracer, data); err == nil || !strings.Contains(err.Error(), "took longer than 50ms") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	tracer.Report(&bytes.Buffer{}, probes)
+	if buf.Len() == 0 {
+		t.Error("Expected the output written before the timeout")
+	}
+
+	// the calls that enforce the limits are not probed
+	if tmpl, err = ParseTemplateOptions("test", `{{define "a"}}a{{end}}{{range .}}{{template "a"}}{{end}}`, Options{Limits: Limits{MaxDepth: 2, Timeout: time.Second}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range Instrument(tmpl.Template) {
+		if strings.Contains(p.Source, "gengen") {
+			t.Errorf("Unexpected probe %v", p)
+		}
+	}
+
+	// shared sections count towards the output, once
+	text = `{{define "s"}}{{range .}}0123456789{{end}}{{end}}x{{shared "s" .}}`
+	if tmpl, err = ParseTemplateOptions("test", text, Options{Limits: Limits{MaxOutput: 95}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tmpl.ExecuteShared(&bytes.Buffer{}, data); err == nil || !strings.Contains(err.Error(), "larger than the limit of 95 bytes") {
+		t.Errorf("Expected the shared section to be too large, got %v", err)
+	}
+	buf.Reset()
+	if err = tmpl.Execute(&buf, data[:9]); err != nil || buf.Len() != 91 {
+		t.Errorf("Unexpected output of %d bytes %v", buf.Len(), err)
+	}
+	buf.Reset()
+	if sections, err := tmpl.ExecuteShared(&buf, data[:9]); err != nil || buf.String() != "x" || len(sections) != 1 {
+		t.Errorf("Unexpected output %q %v %v", buf.String(), sections, err)
+	}
+}
+
+func TestRestricted(t *testing.T) {
+	for _, ref := range []string{"/etc/x.tmpl", "../x.tmpl", "a/../../x.tmpl", "$HOME/x.tmpl"} {
+		if _, err := ParseTemplateOptions("test", `{{extends "`+ref+`"}}`, Options{Restricted: true}); err == nil || !strings.Contains(err.Error(), "restricted") {
+			t.Errorf("Expected extending %q to be refused, got %v", ref, err)
+		}
+	}
+	for _, text := range []string{`{{extends "lib:maps/slice_map"}}`, `{{ucFirst "a"}}`} {
+		if _, err := ParseTemplateOptions("test", text, Options{Restricted: true}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// relative paths that lead out of the directory of the template, through a module or a symlink, are refused
+	dir, outside := t.TempDir(), t.TempDir()
+	for path, text := range map[string]string{"a.tmpl": "a", "sub/b.tmpl": "b", outside + "/c.tmpl": "c"} {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+	}
+	name := filepath.Join(dir, "test.tmpl")
+	for _, ref := range []string{"github.com/goradd/gengen/templates/map_src/slice_map.tmpl", "link/c.tmpl"} {
+		if _, err := ParseTemplateOptions(name, `{{extends "`+ref+`"}}`, Options{Restricted: true}); err == nil || !strings.Contains(err.Error(), "restricted") {
+			t.Errorf("Expected extending %q to be refused, got %v", ref, err)
+		}
+	}
+	for _, ref := range []string{"a.tmpl", "sub/b.tmpl"} {
+		if _, err := ParseTemplateOptions(name, `{{extends "`+ref+`"}}`, Options{Restricted: true}); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+	"text/template/parse"
+)
+
+// An Issue is a problem found by Lint.
+type Issue struct {
+	File string
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.File, i.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", i.File, i.Line, i.Col, i.Msg)
+}
+
+// Lint statically checks a template against the configuration files that drive it, without executing it.
+// configs maps the name of each configuration file to its content. It reports:
+//   - keys the template refers to that no configuration defines,
+//   - keys a configuration defines that the template never refers to,
+//   - templates that are called but never defined, and
+//   - comparisons that will fail or cannot vary, such as comparing a configuration value to a literal of a
+//     different type.
+//
+// Only references to the top level of the dot context are checked. References made where dot has been
+// changed by a range or with statement, or inside a template called with something other than dot, are
+// not known to be configuration keys and are skipped. So is the Data key, which data files are bound to.
+func Lint(t *template.Template, configs map[string][]byte) (issues []Issue) {
+	return lint(t, configs, nil)
+}
+
+// Lint checks the template like the Lint function does, taking the params of the template into account.
+// Keys that have a param with a default are defined, the values of the configurations must suit the
+// types of their params, and if the template declares params, it must not refer to other keys.
+func (t *Template) Lint(configs map[string][]byte) []Issue {
+	return lint(t.Template, configs, t.FrontMatter.Params)
+}
+
+// Diagnose checks a template by itself, without configuration files, the way an editor can as the template is
+// written. It reports templates that are called but never defined, and if the template declares params,
+// references to keys that are not among them.
+func (t *Template) Diagnose() []Issue {
+	l := newLinter(t.Template)
+	issues := append(l.issues, l.undeclared(t.FrontMatter.Params)...)
+	sortIssues(issues)
+	return issues
+}
+
+// Keys returns the configuration keys that the template refers to, in sorted order. They are found the way
+// Lint finds them.
+func (t *Template) Keys() []string {
+	var keys []string
+	for key := range newLinter(t.Template).refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newLinter returns a linter that has walked all of t.
+func newLinter(t *template.Template) *linter {
+	l := &linter{t: t, refs: make(map[string]lintRef), walked: make(map[string]bool)}
+	l.walk(t, true)
+	// walk the templates that are never called with dot, so that their own problems are found
+	for _, tmpl := range t.Templates() {
+		if !l.walked[tmpl.Name()] {
+			l.walk(tmpl, false)
+		}
+	}
+	return l
+}
+
+func lint(t *template.Template, configs map[string][]byte, params map[string]Param) (issues []Issue) {
+	l := newLinter(t)
+	issues = append(l.issues, l.undeclared(params)...)
+
+	dots := make(map[string]map[string]interface{}, len(configs))
+	var names []string
+	for name, data := range configs {
+		dot, err := ParseConfig(data)
+		if err != nil {
+			issues = append(issues, Issue{File: name, Msg: err.Error()})
+			continue
+		}
+		// the processors are not passed to the template
+		m, _ := dot.(map[string]interface{})
+		if _, err = ConfigProcessors(dot); err != nil {
+			issues = append(issues, Issue{File: name, Line: configKeyLines(data)[ProcessKey], Col: 1, Msg: err.Error()})
+			delete(m, ProcessKey)
+		}
+		dots[name] = m
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for key, ref := range l.refs {
+		if key == DataKey {
+			continue // data files are bound when the template is run
+		}
+		defined := params[key].Default != nil
+		for _, name := range names {
+			if _, ok := dots[name][key]; ok {
+				defined = true
+			}
+		}
+		if !defined {
+			issues = append(issues, Issue{ref.file, ref.line, ref.col, fmt.Sprintf("key %q is referenced but never defined", key)})
+		}
+	}
+
+	for _, name := range names {
+		lines := configKeyLines(configs[name])
+		for key, v := range dots[name] {
+			if _, ok := l.refs[key]; !ok {
+				issues = append(issues, Issue{File: name, Line: lines[key], Col: 1, Msg: fmt.Sprintf("key %q is defined but never used", key)})
+			}
+			if p, ok := params[key]; ok {
+				if _, err := p.convert(v); err != nil {
+					issues = append(issues, Issue{File: name, Line: lines[key], Col: 1, Msg: fmt.Sprintf("key %q: %s", key, err)})
+				}
+			}
+		}
+		for key, p := range params {
+			if _, ok := dots[name][key]; !ok && p.Required {
+				issues = append(issues, Issue{File: name, Msg: fmt.Sprintf("key %q is required", key)})
+			}
+		}
+	}
+
+	for _, c := range l.cmps {
+		issues = append(issues, c.check(dots, names)...)
+	}
+
+	sortIssues(issues)
+	return
+}
+
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
+type lintRef struct {
+	file      string
+	line, col int
+}
+
+type linter struct {
+	t    *template.Template
+	tree *parse.Tree
+	// refs holds the first reference to each key, and uses all of them
+	refs   map[string]lintRef
+	uses   []keyUse
+	walked map[string]bool
+	cmps   []comparison
+	issues []Issue
+}
+
+// walk checks a template. root is true if dot, and the $ variable, are the top of the configuration.
+func (l *linter) walk(tmpl *template.Template, root bool) {
+	l.walked[tmpl.Name()] = true
+	if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return
+	}
+	saved := l.tree
+	l.tree = tmpl.Tree
+	l.list(tmpl.Tree.Root, root, root)
+	l.tree = saved
+}
+
+// list checks the nodes in a list. dot is true if dot is the top of the configuration, and dollar
+// is true if the $ variable is.
+func (l *linter) list(n *parse.ListNode, dot, dollar bool) {
+	if n == nil {
+		return
+	}
+	for _, node := range n.Nodes {
+		switch node := node.(type) {
+		case *parse.ActionNode:
+			l.pipe(node.Pipe, dot, dollar)
+		case *parse.IfNode:
+			l.pipe(node.Pipe, dot, dollar)
+			l.list(node.List, dot, dollar)
+			l.list(node.ElseList, dot, dollar)
+		case *parse.RangeNode:
+			l.pipe(node.Pipe, dot, dollar)
+			l.list(node.List, false, dollar)
+			l.list(node.ElseList, dot, dollar)
+		case *parse.WithNode:
+			l.pipe(node.Pipe, dot, dollar)
+			l.list(node.List, false, dollar)
+			l.list(node.ElseList, dot, dollar)
+		case *parse.TemplateNode:
+			l.pipe(node.Pipe, dot, dollar)
+			called := l.t.Lookup(node.Name)
+			if called == nil {
+				file, line, col := location(l.tree, node)
+				l.issues = append(l.issues, Issue{file, line, col, fmt.Sprintf("template %q is not defined", node.Name)})
+			} else if !l.walked[node.Name] && dot && node.Pipe != nil && isDot(node.Pipe) {
+				l.walk(called, true)
+			}
+		}
+	}
+}
+
+func isDot(p *parse.PipeNode) bool {
+	if len(p.Decl) > 0 || len(p.Cmds) != 1 || len(p.Cmds[0].Args) != 1 {
+		return false
+	}
+	_, ok := p.Cmds[0].Args[0].(*parse.DotNode)
+	return ok
+}
+
+func (l *linter) pipe(p *parse.PipeNode, dot, dollar bool) {
+	if p == nil {
+		return
+	}
+	for i, cmd := range p.Cmds {
+		// a comparison later in a pipeline also compares the value piped to it, which we cannot know
+		if i == 0 && len(cmd.Args) > 1 {
+			if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+				switch id.Ident {
+				case "eq", "ne", "lt", "le", "gt", "ge":
+					c := comparison{op: id.Ident, args: cmd.Args[1:], dot: dot, dollar: dollar}
+					c.file, c.line, c.col = location(l.tree, cmd)
+					l.cmps = append(l.cmps, c)
+				}
+			}
+		}
+		for _, arg := range cmd.Args {
+			l.arg(arg, dot, dollar)
+		}
+		l.shared(cmd, dot)
+	}
+}
+
+// shared checks a call to the shared function, which executes a template like a template action does.
+func (l *linter) shared(cmd *parse.CommandNode, dot bool) {
+	if id, ok := cmd.Args[0].(*parse.IdentifierNode); !ok || id.Ident != "shared" || len(cmd.Args) != 3 {
+		return
+	}
+	name, ok := cmd.Args[1].(*parse.StringNode)
+	if !ok {
+		return
+	}
+	called := l.t.Lookup(name.Text)
+	if called == nil {
+		file, line, col := location(l.tree, name)
+		l.issues = append(l.issues, Issue{file, line, col, fmt.Sprintf("template %q is not defined", name.Text)})
+	} else if _, isDot := cmd.Args[2].(*parse.DotNode); !l.walked[name.Text] && dot && isDot {
+		l.walk(called, true)
+	}
+}
+
+func (l *linter) arg(n parse.Node, dot, dollar bool) {
+	switch n := n.(type) {
+	case *parse.FieldNode:
+		if dot {
+			l.ref(n.Ident[0], n)
+		}
+	case *parse.VariableNode:
+		if dollar && n.Ident[0] == "$" && len(n.Ident) > 1 {
+			l.ref(n.Ident[1], n)
+		}
+	case *parse.ChainNode:
+		l.arg(n.Node, dot, dollar)
+	case *parse.PipeNode:
+		l.pipe(n, dot, dollar)
+	}
+}
+
+func (l *linter) ref(key string, n parse.Node) {
+	file, line, col := location(l.tree, n)
+	if _, ok := l.refs[key]; !ok {
+		l.refs[key] = lintRef{file, line, col}
+	}
+	l.uses = append(l.uses, keyUse{key, lintRef{file, line, col}})
+}
+
+// A keyUse is a reference to a key.
+type keyUse struct {
+	key string
+	lintRef
+}
+
+// undeclared reports the references to keys that are not among params, if there are any params.
+func (l *linter) undeclared(params map[string]Param) (issues []Issue) {
+	if len(params) == 0 {
+		return nil
+	}
+	for _, u := range l.uses {
+		if _, ok := params[u.key]; !ok && u.key != DataKey {
+			issues = append(issues, Issue{u.file, u.line, u.col, fmt.Sprintf("key %q is not one of the params of the template", u.key)})
+		}
+	}
+	return
+}
+
+// A comparison is a call to one of the comparison functions found in the template.
+type comparison struct {
+	lintRef
+	op          string
+	args        []parse.Node
+	dot, dollar bool
+}
+
+// check reports problems with the comparison given the configurations.
+func (c comparison) check(dots map[string]map[string]interface{}, names []string) (issues []Issue) {
+	report := func(format string, args ...interface{}) {
+		issues = append(issues, Issue{c.file, c.line, c.col, fmt.Sprintf(format, args...)})
+	}
+
+	var keys []string
+	for _, arg := range c.args {
+		if key := c.key(arg); key != "" {
+			keys = append(keys, key)
+		} else if literalKind(arg) == "" {
+			return // the outcome depends on something we cannot know statically
+		}
+	}
+	if len(keys) == 0 {
+		report("%s compares only literals, so its result never changes", c.op)
+		return
+	}
+	if len(keys) == 2 && len(c.args) == 2 && keys[0] == keys[1] {
+		report("%s compares .%s with itself", c.op, keys[0])
+		return
+	}
+
+	for _, name := range names {
+		var kinds []string
+		for _, arg := range c.args {
+			kind := literalKind(arg)
+			if key := c.key(arg); key != "" {
+				v, ok := dots[name][key]
+				if !ok {
+					continue // missing keys are reported elsewhere
+				}
+				kind = valueKind(v)
+				if kind == "object" || kind == "array" {
+					report("%s cannot compare .%s, which is an %s in %s", c.op, key, kind, name)
+					return
+				}
+			}
+			kinds = append(kinds, kind)
+		}
+		for i := 1; i < len(kinds); i++ {
+			kind := kinds[i]
+			if kind != kinds[0] && kind != "null" && kinds[0] != "null" {
+				report("%s compares a %s with a %s in %s", c.op, kinds[0], kind, name)
+				return
+			}
+		}
+	}
+	return
+}
+
+// key returns the configuration key that n refers to, if it refers to one.
+func (c comparison) key(n parse.Node) string {
+	switch n := n.(type) {
+	case *parse.FieldNode:
+		if c.dot && len(n.Ident) == 1 {
+			return n.Ident[0]
+		}
+	case *parse.VariableNode:
+		if c.dollar && len(n.Ident) == 2 && n.Ident[0] == "$" {
+			return n.Ident[1]
+		}
+	}
+	return ""
+}
+
+// literalKind returns the json kind of a template literal, or an empty string if n is not a literal.
+func literalKind(n parse.Node) string {
+	switch n.(type) {
+	case *parse.StringNode:
+		return "string"
+	case *parse.NumberNode:
+		return "number"
+	case *parse.BoolNode:
+		return "boolean"
+	case *parse.NilNode:
+		return "null"
+	}
+	return ""
+}
+
+// valueKind returns the json kind of a decoded configuration value.
+func valueKind(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64, int64, uint64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// configKeyLines returns the line number of each top level key in the text of a configuration file.
+func configKeyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	idx := bytes.IndexRune(data, '{')
+	if idx < 0 {
+		return lines
+	}
+	dec := json.NewDecoder(bytes.NewReader(data[idx:]))
+	var depth int
+	var expectKey bool
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err != nil {
+			return lines
+		}
+		if d, ok := tok.(json.Delim); ok {
+			if d == '{' || d == '[' {
+				depth++
+			} else {
+				depth--
+			}
+			// a key comes next on entering the top object, or after a value nested in it
+			expectKey = depth == 1
+			continue
+		}
+		if depth != 1 {
+			continue
+		}
+		if key, ok := tok.(string); ok && expectKey {
+			// offset is the end of the previous token, so skip to the key itself
+			start := idx + int(offset)
+			start += bytes.IndexByte(data[start:], '"')
+			lines[key] = 1 + bytes.Count(data[:start], []byte{'\n'})
+		}
+		expectKey = !expectKey
+	}
+}
@@ -0,0 +1,63 @@
+package gengen
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tmpl, err := ParseTemplate("test", `{{.a}}{{if eq .b "x"}}{{end}}{{range .list}}{{.notAKey}}{{$.c}}{{end}}{{template "t" .}}{{define "t"}}{{.d}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := `/* comment */
+{
+  "a": 1,
+  "b": true,
+  "list": [],
+  "unused": {"nested": 1},
+  "Postprocess": [{"type": "trim"}]
+}`
+	bad := `{"a": 1, "b": "x", "list": [],
+"Postprocess": [{"type": "teleport"}]}`
+
+	issues := Lint(tmpl.Template, map[string][]byte{"config.json": []byte(config), "bad.json": []byte(bad)})
+	var s []string
+	for _, issue := range issues {
+		s = append(s, issue.String())
+	}
+	expected := []string{
+		`bad.json:2:1: Postprocess 1: unknown processor type "teleport"`,
+		`config.json:6:1: key "unused" is defined but never used`,
+		`test:1:11: eq compares a boolean with a string in config.json`,
+		`test:1:59: key "c" is referenced but never defined`,
+		`test:1:104: key "d" is referenced but never defined`,
+	}
+	if len(s) != len(expected) {
+		t.Fatalf("Expected %d issues, got:\n%v", len(expected), s)
+	}
+	for i := range s {
+		if s[i] != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], s[i])
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	text := `{{/*gengen {"params": {"a": {"type": "int"}}} */}}` + "\n" + `{{.a}} {{.b}}{{range .a}}{{.c}}{{end}}{{template "x" .}}`
+	tmpl, err := ParseTemplate("test", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s []string
+	for _, issue := range tmpl.Diagnose() {
+		s = append(s, issue.String())
+	}
+	expected := `[test:2:9: key "b" is not one of the params of the template test:2:49: template "x" is not defined]`
+	if fmt.Sprint(s) != expected {
+		t.Errorf("Expected %s, got %v", expected, s)
+	}
+	if keys := fmt.Sprint(tmpl.Keys()); keys != "[a b]" {
+		t.Errorf("Unexpected keys %s", keys)
+	}
+}
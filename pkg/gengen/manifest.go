@@ -0,0 +1,103 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// A Manifest lists the jobs of a batch run of gengen. Jobs that have the same output file are combined into
+// that file, in the order they are listed.
+type Manifest struct {
+	Jobs []Job `json:"jobs"`
+}
+
+// A Job is one execution of a template.
+type Job struct {
+	// Template is the template file, which can also be a reference to a template in the Library.
+	Template string `json:"template"`
+	// Config is the configuration file that provides the dot context of the template.
+	Config string `json:"config"`
+	// Output is the file the output is written to.
+	Output string `json:"output"`
+	// Delims are the delimiters of the template, if its front matter does not set them.
+	Delims string `json:"delims,omitempty"`
+	// Data maps names to data files, which are loaded with LoadData and bound to the dot context with BindData.
+	Data map[string]string `json:"data,omitempty"`
+	// Postprocess lists the processors that the output is run through before it is written, after those that
+	// the configuration lists with ProcessKey.
+	Postprocess []Processor `json:"postprocess,omitempty"`
+}
+
+// String describes the job in messages.
+func (j Job) String() string {
+	return filepath.Base(j.Template) + " with " + filepath.Base(j.Config)
+}
+
+// LoadManifest reads the json manifest file at path. Like a configuration file, it can start with a comment.
+// The files of the jobs are found the same way as RealPath finds them, except that relative paths are
+// relative to the directory of the manifest, and templates can also refer to the Library.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := bytes.IndexRune(data, '{')
+	if idx < 0 {
+		return nil, fmt.Errorf("%s: the manifest must contain a json object that starts with an open bracket", path)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data[idx:]))
+	dec.DisallowUnknownFields()
+	m := new(Manifest)
+	if err = dec.Decode(m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range m.Jobs {
+		job := &m.Jobs[i]
+		if job.Template == "" || job.Config == "" || job.Output == "" {
+			return nil, fmt.Errorf("%s: job %d must have a template, a config and an output", path, i+1)
+		}
+		if job.Template, err = ResolveTemplate(job.Template, dir); err == nil {
+			if job.Config, err = resolve(job.Config, dir); err == nil {
+				job.Output, err = resolve(job.Output, dir)
+			}
+		}
+		for name, file := range job.Data {
+			if err == nil {
+				job.Data[name], err = resolve(file, dir)
+			}
+		}
+		for n, p := range job.Postprocess {
+			if err == nil {
+				if err = p.check(); err != nil {
+					err = fmt.Errorf("postprocess %d: %w", n+1, err)
+				}
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: job %d: %w", path, i+1, err)
+		}
+	}
+	if len(m.Jobs) == 0 {
+		return nil, errors.New(path + ": the manifest has no jobs")
+	}
+	return m, nil
+}
+
+// Outputs returns the output files of the manifest, each with the jobs that produce it, in the order that
+// they first appear.
+func (m *Manifest) Outputs() (outputs []string, jobs map[string][]Job) {
+	jobs = make(map[string][]Job)
+	for _, job := range m.Jobs {
+		if _, ok := jobs[job.Output]; !ok {
+			outputs = append(outputs, job.Output)
+		}
+		jobs[job.Output] = append(jobs[job.Output], job)
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package gengen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gengen.json")
+	manifest := `/* a comment */
+{"jobs": [
+	{"template": "lib:maps/slice_map", "config": "a.json", "output": "out.go"},
+	{"template": "b.tmpl", "config": "b.json", "output": "other.go"},
+	{"template": "c.tmpl", "config": "c.json", "output": "out.go"}
+]}`
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs, jobs := m.Outputs()
+	if len(outputs) != 2 || outputs[0] != filepath.Join(dir, "out.go") || len(jobs[outputs[0]]) != 2 {
+		t.Errorf("Unexpected outputs %v %v", outputs, jobs)
+	}
+	if job := jobs[outputs[0]][0]; filepath.Base(job.Template) != "slice_map.tmpl" || job.Config != filepath.Join(dir, "a.json") {
+		t.Errorf("Unexpected job %v", job)
+	}
+
+	if err = os.WriteFile(path, []byte(`{"jobs": [{"template": "a.tmpl", "config": "a.json", "out": "a.go"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadManifest(path); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
@@ -0,0 +1,164 @@
+package gengen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// OutputsFile is the name of the file that records the files gengen generated from the manifests and the
+// go:generate lines of a directory, so that the ones that are no longer generated can be removed.
+const OutputsFile = ".gengen-outputs"
+
+// CommandLine is the source of the files generated by runs of gengen without a manifest, like the ones in
+// go:generate lines.
+const CommandLine = "-"
+
+const outputsHeader = "# Files generated by gengen, each after the manifest that generates it, or - for the command line.\n" +
+	"# gengen clean removes the ones that are no longer generated.\n"
+
+// generatedRE matches the header that marks a file as generated by gengen, in the comment syntax of any language.
+var generatedRE = regexp.MustCompile(`(?m)^\W*Code generated by gengen\b.*DO NOT EDIT\b`)
+
+// IsGenerated returns true if data has the header that marks a file as generated by gengen, like
+// "// Code generated by gengen. DO NOT EDIT.". gengen only removes files that have it.
+func IsGenerated(data []byte) bool {
+	return generatedRE.Match(data)
+}
+
+// An OutputIndex is the record of the files generated from a directory, which is kept in its OutputsFile.
+type OutputIndex struct {
+	// Dir is the directory. The paths of the files are relative to it in the OutputsFile.
+	Dir string
+	// sources maps the absolute paths of the files to their sources.
+	sources map[string]string
+}
+
+// LoadOutputIndex reads the OutputsFile of dir. If there is none, the index is empty.
+func LoadOutputIndex(dir string) (*OutputIndex, error) {
+	x := &OutputIndex{Dir: dir, sources: make(map[string]string)}
+	path := filepath.Join(dir, OutputsFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return x, nil
+	} else if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "\t")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s:%d: expected a source and a file separated by a tab", path, n)
+		}
+		x.sources[filepath.Join(dir, filepath.FromSlash(line[i+1:]))] = line[:i]
+	}
+	return x, nil
+}
+
+// Add records that source generated the files at the given absolute paths. source is CommandLine, or
+// the path of a manifest.
+func (x *OutputIndex) Add(source string, paths ...string) {
+	source = x.source(source)
+	for _, path := range paths {
+		x.sources[path] = source
+	}
+}
+
+// source returns the name of a source in the index, which for a manifest is its path relative to Dir.
+func (x *OutputIndex) source(source string) string {
+	if source != CommandLine && filepath.IsAbs(source) {
+		if rel, err := filepath.Rel(x.Dir, source); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return source
+}
+
+// Remove removes a file from the index.
+func (x *OutputIndex) Remove(path string) {
+	delete(x.sources, path)
+}
+
+// Sources returns the sources of the files in the index, in sorted order.
+func (x *OutputIndex) Sources() []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, source := range x.sources {
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// Stale returns the files recorded for source, which is CommandLine or the path of a manifest, that it no longer
+// generates, in sorted order. For a manifest, those are the files that are not outputs of its jobs, or all of them
+// if the manifest was removed. For CommandLine, they are the files that are not the output of a go:generate line
+// in Dir that runs gengen.
+func (x *OutputIndex) Stale(source string) ([]string, error) {
+	source = x.source(source)
+	var current []string
+	var err error
+	if source == CommandLine {
+		current, err = GenerateOutputs(x.Dir)
+	} else {
+		path := filepath.Join(x.Dir, filepath.FromSlash(source))
+		var m *Manifest
+		if m, err = LoadManifest(path); err == nil {
+			current, _ = m.Outputs()
+		} else if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return x.stale(source, current), nil
+}
+
+// stale returns the files recorded for source that are not in current.
+func (x *OutputIndex) stale(source string, current []string) []string {
+	keep := make(map[string]bool)
+	for _, path := range current {
+		keep[path] = true
+	}
+	var stale []string
+	for path, s := range x.sources {
+		if s == source && !keep[path] {
+			stale = append(stale, path)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// Save writes the index to the OutputsFile of its directory, or removes that file if the index is empty.
+func (x *OutputIndex) Save() error {
+	path := filepath.Join(x.Dir, OutputsFile)
+	if len(x.sources) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var lines []string
+	for file, source := range x.sources {
+		if rel, err := filepath.Rel(x.Dir, file); err == nil {
+			file = rel
+		}
+		lines = append(lines, source+"\t"+filepath.ToSlash(file)+"\n")
+	}
+	sort.Strings(lines)
+	return WriteFile(path, []byte(outputsHeader+strings.Join(lines, "")))
+}
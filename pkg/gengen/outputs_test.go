@@ -0,0 +1,94 @@
+package gengen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsGenerated(t *testing.T) {
+	tests := map[string]bool{
+		"// Code generated by gengen. DO NOT EDIT.\n\npackage a\n":   true,
+		"# Code generated by gengen. DO NOT EDIT.\nkey: value\n":     true,
+		"<!-- Code generated by gengen. DO NOT EDIT. -->\n<p></p>\n": true,
+		"package a\n": false,
+		"// Code generated by stringer. DO NOT EDIT.\n": false,
+		"// Code generated by gengen.\n":                false,
+	}
+	for text, want := range tests {
+		if got := IsGenerated([]byte(text)); got != want {
+			t.Errorf("IsGenerated(%q) = %v", text, got)
+		}
+	}
+}
+
+func TestOutputIndex(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("gen.go", "package a\n\n//go:generate gengen -c a.json -o a.go a.tmpl\n//go:generate go run github.com/goradd/gengen -c b.json \"-o=b $GOFILE.go\" b.tmpl\n//go:generate stringer -o c.go\n")
+	write("m.json", `{"jobs": [{"template": "a.tmpl", "config": "a.json", "output": "m1.go"}]}`)
+
+	outputs, err := GenerateOutputs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "b gen.go.go")}
+	if !reflect.DeepEqual(outputs, want) {
+		t.Errorf("Expected outputs %v, got %v", want, outputs)
+	}
+
+	x, err := LoadOutputIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Add(CommandLine, filepath.Join(dir, "a.go"), filepath.Join(dir, "old.go"))
+	x.Add(filepath.Join(dir, "m.json"), filepath.Join(dir, "m1.go"), filepath.Join(dir, "sub", "m2.go"))
+	if err = x.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if x, err = LoadOutputIndex(dir); err != nil {
+		t.Fatal(err)
+	}
+	if sources := x.Sources(); !reflect.DeepEqual(sources, []string{"-", "m.json"}) {
+		t.Errorf("Unexpected sources %v", sources)
+	}
+	stale, err := x.Stale(CommandLine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, []string{filepath.Join(dir, "old.go")}) {
+		t.Errorf("Unexpected stale files %v", stale)
+	}
+	if stale, err = x.Stale(filepath.Join(dir, "m.json")); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, []string{filepath.Join(dir, "sub", "m2.go")}) {
+		t.Errorf("Unexpected stale files %v", stale)
+	}
+
+	if err = os.Remove(filepath.Join(dir, "m.json")); err != nil {
+		t.Fatal(err)
+	}
+	if stale, err = x.Stale("m.json"); err != nil || len(stale) != 2 {
+		t.Errorf("Expected all the files of a removed manifest to be stale, got %v %v", stale, err)
+	}
+
+	for _, source := range x.Sources() {
+		stale, _ = x.Stale(source)
+		for _, path := range stale {
+			x.Remove(path)
+		}
+	}
+	x.Remove(filepath.Join(dir, "a.go"))
+	if err = x.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, OutputsFile)); !os.IsNotExist(err) {
+		t.Error("Expected an empty index to be removed")
+	}
+}
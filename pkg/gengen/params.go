@@ -0,0 +1,181 @@
+package gengen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// A Param describes a configuration value that a template uses. Templates declare them in the params
+// setting of their front matter, which maps the keys of the configuration to their descriptions:
+//
+//	{{/*gengen {"params": {"timeout": {"type": "duration", "default": "5s", "doc": "How long to wait."}}} */}}
+type Param struct {
+	// Type is the type of the value, which is one of:
+	//   - "string", "bool", "list" or "object", for json values of those kinds,
+	//   - "int" or "uint", for whole numbers, which become an int64 or a uint64,
+	//   - "float", for any number, which becomes a float64,
+	//   - "number", for any number, which is left as ParseConfig decodes it,
+	//   - "duration", for a string like "1h30m", which becomes a time.Duration,
+	//   - "time", for a string like "2006-01-02T15:04:05Z07:00", which becomes a time.Time,
+	//   - "date", for a string like "2006-01-02", which becomes a time.Time, or
+	//   - "any" or an empty string, for a value of any kind.
+	Type string `json:"type,omitempty"`
+	// Default is the value used when the configuration does not have one.
+	Default interface{} `json:"default,omitempty"`
+	// Required is true if the configuration must have the value.
+	Required bool `json:"required,omitempty"`
+	// Doc describes the value.
+	Doc string `json:"doc,omitempty"`
+}
+
+var paramTypes = map[string]bool{
+	"": true, "any": true, "string": true, "bool": true, "list": true, "object": true, "int": true,
+	"uint": true, "float": true, "number": true, "duration": true, "time": true, "date": true,
+}
+
+// check reports a problem with the declaration of a param.
+func (p Param) check() error {
+	if !paramTypes[p.Type] {
+		return fmt.Errorf("unknown type %q", p.Type)
+	}
+	if p.Default != nil {
+		if _, err := p.convert(p.Default); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// ApplyParams checks the values of dot, which is normally a configuration, against the params the template
+// declares. It converts the values to the types of their params, and adds the defaults of the params whose
+// values dot does not have.
+func (t *Template) ApplyParams(dot interface{}) error {
+	if len(t.FrontMatter.Params) == 0 {
+		return nil
+	}
+	m, ok := dot.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: the template declares params, so the configuration must be a json object", t.Name())
+	}
+	var keys []string
+	for key := range t.FrontMatter.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p := t.FrontMatter.Params[key]
+		v, ok := m[key]
+		if !ok {
+			if p.Required {
+				return fmt.Errorf("%s: the configuration must have a value for %q", t.Name(), key)
+			}
+			if p.Default == nil {
+				continue
+			}
+			v = p.Default
+		}
+		v, err := p.convert(v)
+		if err != nil {
+			return fmt.Errorf("%s: %q: %w", t.Name(), key, err)
+		}
+		m[key] = v
+	}
+	return nil
+}
+
+// convert converts a decoded json value to the type of the param.
+func (p Param) convert(v interface{}) (interface{}, error) {
+	v = numbers(v)
+	if v == nil {
+		return nil, nil
+	}
+	wrong := func() (interface{}, error) {
+		return nil, fmt.Errorf("expected a value of type %s, but found the %s %v", p.Type, valueKind(v), v)
+	}
+	switch p.Type {
+	case "string":
+		if _, ok := v.(string); !ok {
+			return wrong()
+		}
+	case "bool":
+		if _, ok := v.(bool); !ok {
+			return wrong()
+		}
+	case "list":
+		if _, ok := v.([]interface{}); !ok {
+			return wrong()
+		}
+	case "object":
+		if _, ok := v.(map[string]interface{}); !ok {
+			return wrong()
+		}
+	case "int":
+		switch n := v.(type) {
+		case int64:
+		case uint64, json.Number:
+			return nil, fmt.Errorf("%v is too large for an int", v)
+		case float64:
+			if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+				return nil, fmt.Errorf("%v is not a whole number that fits in an int", v)
+			}
+			return int64(n), nil
+		default:
+			return wrong()
+		}
+	case "uint":
+		switch n := v.(type) {
+		case int64:
+			if n < 0 {
+				return nil, fmt.Errorf("%v is negative", v)
+			}
+			return uint64(n), nil
+		case uint64:
+		case json.Number:
+			return nil, fmt.Errorf("%v is too large for a uint", v)
+		case float64:
+			if n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 {
+				return nil, fmt.Errorf("%v is not a whole number that fits in a uint", v)
+			}
+			return uint64(n), nil
+		default:
+			return wrong()
+		}
+	case "float":
+		switch n := v.(type) {
+		case int64:
+			return float64(n), nil
+		case uint64:
+			return float64(n), nil
+		case json.Number:
+			f, _ := n.Float64()
+			return f, nil
+		case float64:
+		default:
+			return wrong()
+		}
+	case "number":
+		if valueKind(v) != "number" {
+			return wrong()
+		}
+	case "duration":
+		s, ok := v.(string)
+		if !ok {
+			return wrong()
+		}
+		return time.ParseDuration(s)
+	case "time", "date":
+		s, ok := v.(string)
+		if !ok {
+			return wrong()
+		}
+		layout := time.RFC3339
+		if p.Type == "date" {
+			layout = "2006-01-02"
+		}
+		return time.Parse(layout, s)
+	}
+	return v, nil
+}
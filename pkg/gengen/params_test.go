@@ -0,0 +1,77 @@
+package gengen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNumbers(t *testing.T) {
+	dot, err := ParseConfig([]byte(`{"a": 12345678901234567, "b": 18446744073709551615, "c": 123456789012345678901234567890, "d": 1.5, "e": [2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := ParseTemplate("test", `{{.a}} {{.b}} {{.c}} {{.d}} {{index .e 0}} {{if eq .a 12345678901234567}}eq{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, dot); err != nil {
+		t.Fatal(err)
+	}
+	expected := "12345678901234567 18446744073709551615 123456789012345678901234567890 1.5 2 eq"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestParams(t *testing.T) {
+	text := `{{/*gengen {"params": {
+	"timeout": {"type": "duration", "default": "1m30s"},
+	"start": {"type": "date", "required": true},
+	"size": {"type": "uint", "default": 10},
+	"ratio": {"type": "float"}
+}} */ -}}
+{{.timeout.Seconds}} {{.start.Year}} {{.size}} {{.ratio}}`
+	tmpl, err := ParseTemplate("test", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dot, _ := ParseConfig([]byte(`{"start": "2020-02-03", "ratio": 2}`))
+	if err = tmpl.ApplyParams(dot); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, dot); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "90 2020 10 2" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+
+	for config, msg := range map[string]string{
+		`{"ratio": 1}`:                             `must have a value for "start"`,
+		`{"start": "2020-02-03", "size": -1}`:      `"size": -1 is negative`,
+		`{"start": "2020-02-03", "timeout": true}`: `"timeout": expected a value of type duration, but found the boolean true`,
+	} {
+		dot, _ = ParseConfig([]byte(config))
+		if err = tmpl.ApplyParams(dot); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected an error containing %q, got %v", msg, err)
+		}
+	}
+
+	issues := tmpl.Lint(map[string][]byte{"config.json": []byte(`{"start": 1, "ratio": 1}`)})
+	if len(issues) != 1 || !strings.Contains(issues[0].String(), `config.json:1:1: key "start": expected a value of type date`) {
+		t.Errorf("Unexpected issues %v", issues)
+	}
+
+	for _, bad := range []string{
+		`{{/*gengen {"params": {"a": {"type": "color"}}} */}}`,
+		`{{/*gengen {"params": {"a": {"type": "int", "default": "x"}}} */}}`,
+	} {
+		if _, err = ParseTemplate("test", bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package gengen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goradd/gengen/templates"
+	"github.com/goradd/gofile/pkg/sys"
+)
+
+var modules map[string]string
+
+// Library maps the names of the collections of templates that come with gengen to their location.
+// Refer to a template in a collection as "lib:<collection>/<template>", for example "lib:maps/slice_map".
+var Library = map[string]string{
+	"maps": "github.com/goradd/gengen/templates/map_src",
+}
+
+// libPrefix begins a reference to a template in the Library.
+const libPrefix = "lib:"
+
+// libraryRoot is the package of the templates of the Library, whose directories are built into gengen.
+const libraryRoot = "github.com/goradd/gengen/templates/"
+
+// embedded is the copy of the templates built into gengen, which is used when the project does not require
+// the gengen module.
+var embedded struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+// RealPath returns the absolute path of the given path after expanding environment variables in it and
+// substituting the location of any module or package path it begins with. For example,
+// "github.com/goradd/gengen/templates/map_src/safe_test.json" becomes the location of that file on disk.
+func RealPath(path string) (string, error) {
+	return resolve(path, "")
+}
+
+// ResolveTemplate returns the path of the template file that ref refers to. ref is either a reference to a
+// template in the Library, or a path that is treated like RealPath treats it, except that a relative
+// file path is relative to dir.
+func ResolveTemplate(ref, dir string) (string, error) {
+	if strings.HasPrefix(ref, libPrefix) {
+		parts := strings.SplitN(ref[len(libPrefix):], "/", 2)
+		if len(parts) < 2 || !fs.ValidPath(parts[1]) {
+			return "", &os.PathError{Op: "resolve", Path: ref, Err: os.ErrNotExist}
+		}
+		libDir, err := LibraryDir(parts[0])
+		if err != nil {
+			return "", err
+		}
+		path := filepath.Join(libDir, filepath.FromSlash(parts[1]))
+		if filepath.Ext(path) == "" {
+			path += ".tmpl"
+		}
+		return path, nil
+	}
+	return resolve(ref, dir)
+}
+
+// LibraryDir returns the directory of the templates of a collection of the Library. It is the directory in the
+// gengen module if the project requires the module, and otherwise a copy of the templates built into gengen,
+// which is kept in the cache directory of the user.
+func LibraryDir(collection string) (string, error) {
+	location, ok := Library[collection]
+	if !ok {
+		return "", &os.PathError{Op: "resolve", Path: libPrefix + collection, Err: os.ErrNotExist}
+	}
+	if dir, err := resolve(location, ""); err == nil {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	embedded.once.Do(func() {
+		embedded.dir, embedded.err = extractLibrary()
+	})
+	if embedded.err != nil {
+		return "", fmt.Errorf("could not copy the Library: %w", embedded.err)
+	}
+	return filepath.Join(embedded.dir, filepath.FromSlash(strings.TrimPrefix(location, libraryRoot))), nil
+}
+
+// extractLibrary writes the templates built into gengen to the cache directory of the user, unless they are
+// already there, and returns the directory. The directory is named after the hash of the templates, so that
+// different versions of gengen do not share one.
+func extractLibrary() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	files := make(map[string][]byte)
+	var names []string
+	h := sha256.New()
+	err = fs.WalkDir(templates.Library, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := templates.Library.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %d\n", name, len(data))
+		h.Write(data)
+		files[name] = data
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(cache, "gengen", "library", hex.EncodeToString(h.Sum(nil))[:16])
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if _, err = os.Stat(path); err == nil {
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", err
+		}
+		// write to a temporary file and rename it, so that a gengen running at the same time never reads part of it
+		tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+		if err != nil {
+			return "", err
+		}
+		_, err = tmp.Write(files[name])
+		if err2 := tmp.Close(); err == nil {
+			err = err2
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func resolve(path, dir string) (string, error) {
+	var err error
+	if modules == nil {
+		if modules, err = sys.ModulePaths(); err != nil {
+			return "", err
+		}
+	}
+
+	path = os.ExpandEnv(path)
+	var modPath string
+	if modPath, err = sys.GetModulePath(path, modules); err != nil {
+		return "", err
+	}
+	if modPath == filepath.FromSlash(path) && !filepath.IsAbs(modPath) && dir != "" {
+		modPath = filepath.Join(dir, modPath)
+	}
+	return filepath.Abs(modPath)
+}
@@ -0,0 +1,183 @@
+package gengen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Names of the template functions that instrumented templates call.
+const (
+	probeFunc = "gengenProbe"
+	condFunc  = "gengenCond"
+	endFunc   = "gengenEnd"
+)
+
+// A Probe is a point in a template that reports when execution reaches it. There is a probe at every action
+// and at the start of every branch of an if, range or with statement, including the implied
+// empty else branch of statements that do not have one.
+type Probe struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Col  int    `json:"col"`
+	// Kind is "action", "template", or the statement keyword for a branch, followed by "-else" for its else branch.
+	Kind string `json:"kind"`
+	// Source is an excerpt of the template text at the probe.
+	Source string `json:"source"`
+}
+
+func (p Probe) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s %s", p.File, p.Line, p.Col, p.Kind, p.Source)
+}
+
+// A Monitor observes the execution of an instrumented template.
+type Monitor interface {
+	// Probe is called when execution reaches the probe with the given id. val is the value of the pipeline of
+	// the action or statement the probe belongs to, and is nil for template calls.
+	Probe(id int, val interface{})
+	// End is called when execution leaves the part of the template covered by the probe with the given id.
+	// It is not called for a range iteration ended early by break or continue.
+	End(id int)
+}
+
+// Instrument inserts probes into every template associated with t, and returns them. As the template executes,
+// the monitors are told about each probe reached, identified by its index in the returned slice.
+// Instrument must be called after parsing and before the first execution, and only once per template.
+func Instrument(t *template.Template, monitors ...Monitor) []Probe {
+	in := instrumenter{}
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+			continue
+		}
+		in.tree = tmpl.Tree
+		in.list(tmpl.Tree.Root)
+	}
+
+	// conds holds the latest pipeline value of each statement, keyed by the id of its first probe
+	conds := make(map[int]interface{})
+	t.Funcs(template.FuncMap{
+		probeFunc: func(id int, val ...interface{}) interface{} {
+			var v interface{}
+			if len(val) > 0 {
+				v = val[0]
+			} else if stmt, ok := in.stmts[id]; ok {
+				v = conds[stmt]
+			}
+			for _, m := range monitors {
+				m.Probe(id, v)
+			}
+			if len(val) > 0 {
+				return val[0]
+			}
+			return ""
+		},
+		condFunc: func(id int, val interface{}) interface{} {
+			conds[id] = val
+			return val
+		},
+		endFunc: func(id int) string {
+			for _, m := range monitors {
+				m.End(id)
+			}
+			return ""
+		},
+	})
+	return in.probes
+}
+
+type instrumenter struct {
+	tree   *parse.Tree
+	probes []Probe
+	// stmts maps the id of each branch probe to the id of the first probe of its statement
+	stmts map[int]int
+}
+
+func (in *instrumenter) list(l *parse.ListNode) {
+	nodes := make([]parse.Node, 0, 2*len(l.Nodes))
+	for _, n := range l.Nodes {
+		switch n := n.(type) {
+		case *parse.ActionNode:
+			if isLimitCall(n) {
+				break
+			}
+			id := in.probe(n, "action", n.String())
+			n.Pipe.Cmds = append(n.Pipe.Cmds, in.call(probeFunc, id).Pipe.Cmds[0])
+			nodes = append(nodes, n, in.call(endFunc, id))
+			continue
+		case *parse.TemplateNode:
+			id := in.probe(n, "template", n.String())
+			nodes = append(nodes, in.call(probeFunc, id), n, in.call(endFunc, id))
+			continue
+		case *parse.IfNode:
+			in.branch(&n.BranchNode, "if")
+		case *parse.RangeNode:
+			in.branch(&n.BranchNode, "range")
+		case *parse.WithNode:
+			in.branch(&n.BranchNode, "with")
+		}
+		nodes = append(nodes, n)
+	}
+	l.Nodes = nodes
+}
+
+func (in *instrumenter) branch(b *parse.BranchNode, keyword string) {
+	source := "{{" + keyword + " " + b.Pipe.String() + "}}"
+	id := in.probe(b, keyword, source)
+	elseID := in.probe(b, keyword+"-else", source)
+	if in.stmts == nil {
+		in.stmts = make(map[int]int)
+	}
+	in.stmts[id] = id
+	in.stmts[elseID] = id
+	b.Pipe.Cmds = append(b.Pipe.Cmds, in.call(condFunc, id).Pipe.Cmds[0])
+
+	in.list(b.List)
+	b.List.Nodes = append(append([]parse.Node{in.call(probeFunc, id)}, b.List.Nodes...), in.call(endFunc, id))
+
+	if b.ElseList == nil {
+		b.ElseList = &parse.ListNode{NodeType: parse.NodeList, Pos: b.Pos}
+	} else {
+		in.list(b.ElseList)
+	}
+	b.ElseList.Nodes = append(append([]parse.Node{in.call(probeFunc, elseID)}, b.ElseList.Nodes...), in.call(endFunc, elseID))
+}
+
+// probe records a new probe located at n, and returns its id.
+func (in *instrumenter) probe(n parse.Node, kind string, source string) int {
+	p := Probe{Kind: kind, Source: excerpt(source)}
+	p.File, p.Line, p.Col = location(in.tree, n)
+	in.probes = append(in.probes, p)
+	return len(in.probes) - 1
+}
+
+// call returns an action that calls the given instrumentation function with the id of a probe.
+func (in *instrumenter) call(f string, id int) *parse.ActionNode {
+	funcs := map[string]interface{}{f: fmt.Sprint}
+	trees, err := parse.Parse("probe", fmt.Sprintf("{{%s %d}}", f, id), "", "", funcs)
+	if err != nil {
+		panic(err) // the call is our own text, so this would be a bug
+	}
+	return trees["probe"].Root.Nodes[0].(*parse.ActionNode)
+}
+
+// location returns the name of the file, and the line and column, where n is found in the template source.
+func location(tree *parse.Tree, n parse.Node) (file string, line, col int) {
+	loc, _ := tree.ErrorContext(n)
+	// loc is file:line:col, and the file may itself contain colons
+	parts := strings.Split(loc, ":")
+	file = strings.Join(parts[:len(parts)-2], ":")
+	line, _ = strconv.Atoi(parts[len(parts)-2])
+	col, _ = strconv.Atoi(parts[len(parts)-1])
+	return
+}
+
+// excerpt shortens template source to a length suitable for reports.
+func excerpt(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 60 {
+		s = string(r[:57]) + "..."
+	}
+	return s
+}
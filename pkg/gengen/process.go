@@ -0,0 +1,172 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// ProcessKey is the key of a configuration that lists the processors of the outputs of the jobs that use it.
+// The key is not part of the dot context of the template.
+const ProcessKey = "Postprocess"
+
+// A Processor changes the output of a job before it is written. Type says what it does, and the other fields
+// are its settings:
+//   - "header" puts Text, followed by a blank line, at the start of the output, like a license comment.
+//   - "line-endings" changes the line endings of the output to Style, which is "lf" or "crlf".
+//   - "trim" removes the spaces and tabs at the end of each line.
+//   - "build-tag" puts a //go:build line with the build constraint Expr, like "!wasm", at the start of a go
+//     output. If the output already has one, the result requires both.
+//   - "replace" replaces the matches of the regular expression Pattern with Replace, which can refer to
+//     the submatches of the pattern like regexp.Expand does, as in "$1".
+type Processor struct {
+	Type    string `json:"type"`
+	Text    string `json:"text,omitempty"`
+	Style   string `json:"style,omitempty"`
+	Expr    string `json:"expr,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// check returns an error if the processor is not valid.
+func (p Processor) check() error {
+	switch p.Type {
+	case "header", "trim":
+	case "line-endings":
+		if p.Style != "lf" && p.Style != "crlf" {
+			return fmt.Errorf("line-endings style must be \"lf\" or \"crlf\", not %q", p.Style)
+		}
+	case "build-tag":
+		if strings.TrimSpace(p.Expr) == "" {
+			return errors.New("build-tag must have an expr")
+		}
+	case "replace":
+		if p.Pattern == "" {
+			return errors.New("replace must have a pattern")
+		}
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("replace: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown processor type %q", p.Type)
+	}
+	return nil
+}
+
+// Process runs the processors in order on out, which is the output that is about to be written to path.
+// path is empty if the output is not written to a file.
+func Process(path string, out []byte, processors []Processor) ([]byte, error) {
+	for _, p := range processors {
+		var err error
+		if out, err = p.process(path, out); err != nil {
+			if path != "" {
+				return nil, fmt.Errorf("%s: %s: %w", path, p.Type, err)
+			}
+			return nil, fmt.Errorf("%s: %w", p.Type, err)
+		}
+	}
+	return out, nil
+}
+
+func (p Processor) process(path string, out []byte) ([]byte, error) {
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	switch p.Type {
+	case "header":
+		return append([]byte(strings.TrimRight(p.Text, "\n")+"\n\n"), out...), nil
+	case "line-endings":
+		out = bytes.ReplaceAll(out, []byte("\r\n"), []byte("\n"))
+		if p.Style == "crlf" {
+			out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+		}
+		return out, nil
+	case "trim":
+		lines := bytes.SplitAfter(out, []byte("\n"))
+		var buf bytes.Buffer
+		for _, line := range lines {
+			end := len(line) - len(bytes.TrimRight(line, "\r\n"))
+			buf.Write(bytes.TrimRight(line[:len(line)-end], " \t"))
+			buf.Write(line[len(line)-end:])
+		}
+		return buf.Bytes(), nil
+	case "build-tag":
+		return addBuildTag(path, out, strings.TrimSpace(p.Expr))
+	case "replace":
+		return regexp.MustCompile(p.Pattern).ReplaceAll(out, []byte(p.Replace)), nil
+	}
+	return out, nil
+}
+
+// addBuildTag puts a build constraint at the start of a go file.
+func addBuildTag(path string, out []byte, expr string) ([]byte, error) {
+	if path != "" && !strings.HasSuffix(path, ".go") {
+		return nil, errors.New("a build tag can only be added to a go file")
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", out, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	// an existing constraint is in a comment before the package clause
+	for _, g := range f.Comments {
+		if g.Pos() > f.Package {
+			break
+		}
+		for _, c := range g.List {
+			if strings.HasPrefix(c.Text, "//go:build ") {
+				start, end := fset.Position(c.Pos()).Offset, fset.Position(c.End()).Offset
+				combined := "//go:build (" + strings.TrimSpace(c.Text[len("//go:build "):]) + ") && (" + expr + ")"
+				return append(append(append([]byte(nil), out[:start]...), combined...), out[end:]...), nil
+			}
+		}
+	}
+	return append([]byte("//go:build "+expr+"\n\n"), out...), nil
+}
+
+// ConfigProcessors returns the processors that a configuration lists with ProcessKey, and removes them from it.
+func ConfigProcessors(dot interface{}) ([]Processor, error) {
+	m, ok := dot.(map[string]interface{})
+	if !ok || m[ProcessKey] == nil {
+		return nil, nil
+	}
+	// the configuration was decoded generically, so it is encoded again to decode the processors
+	data, err := json.Marshal(m[ProcessKey])
+	if err != nil {
+		return nil, err
+	}
+	var processors []Processor
+	if err = json.Unmarshal(data, &processors); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProcessKey, err)
+	}
+	for i, p := range processors {
+		if err = p.check(); err != nil {
+			return nil, fmt.Errorf("%s %d: %w", ProcessKey, i+1, err)
+		}
+	}
+	delete(m, ProcessKey)
+	return processors, nil
+}
+
+// OutputProcessors returns the processors of an output file that several jobs produce, which are the
+// processors of each job in order, leaving out those that an earlier job already has.
+func OutputProcessors(lists ...[]Processor) []Processor {
+	var all []Processor
+	for _, list := range lists {
+		for _, p := range list {
+			dup := false
+			for _, q := range all {
+				dup = dup || p == q
+			}
+			if !dup {
+				all = append(all, p)
+			}
+		}
+	}
+	return all
+}
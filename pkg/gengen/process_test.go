@@ -0,0 +1,68 @@
+package gengen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProcess(t *testing.T) {
+	src := "// Code generated by gengen. DO NOT EDIT.\n\n//go:build linux\n\npackage a \t\n\nvar FooX = 1  \n"
+	tests := []struct {
+		processors []Processor
+		path, want string
+	}{
+		{[]Processor{{Type: "header", Text: "// License\n"}}, "a.go", "// License\n\n" + src},
+		{[]Processor{{Type: "trim"}}, "a.go", strings.Replace(strings.Replace(src, " \t\n", "\n", 1), "  \n", "\n", 1)},
+		{[]Processor{{Type: "line-endings", Style: "crlf"}, {Type: "line-endings", Style: "lf"}}, "a.go", src},
+		{[]Processor{{Type: "line-endings", Style: "crlf"}, {Type: "trim"}}, "a.go", strings.ReplaceAll(strings.Replace(strings.Replace(src, " \t\n", "\n", 1), "  \n", "\n", 1), "\n", "\r\n")},
+		{[]Processor{{Type: "build-tag", Expr: "!wasm"}}, "a.go", strings.Replace(src, "//go:build linux", "//go:build (linux) && (!wasm)", 1)},
+		{[]Processor{{Type: "build-tag", Expr: "!wasm"}}, "", strings.Replace(src, "//go:build linux", "//go:build (linux) && (!wasm)", 1)},
+		{[]Processor{{Type: "replace", Pattern: `Foo(\w+)`, Replace: "Bar$1"}}, "a.go", strings.Replace(src, "FooX", "BarX", 1)},
+	}
+	for i, test := range tests {
+		out, err := Process(test.path, []byte(src), test.processors)
+		if err != nil {
+			t.Errorf("%d: %v", i, err)
+		} else if string(out) != test.want {
+			t.Errorf("%d: Expected %q, got %q", i, test.want, out)
+		}
+	}
+
+	out, err := Process("a.go", []byte("package a\n"), []Processor{{Type: "build-tag", Expr: "!wasm"}})
+	if err != nil || string(out) != "//go:build !wasm\n\npackage a\n" {
+		t.Errorf("Unexpected output %q %v", out, err)
+	}
+	if _, err = Process("a.txt", []byte("text"), []Processor{{Type: "build-tag", Expr: "!wasm"}}); err == nil {
+		t.Error("Expected an error adding a build tag to a text file")
+	}
+	for _, p := range []Processor{{Type: "unknown"}, {Type: "line-endings"}, {Type: "replace", Pattern: "("}} {
+		if err = p.check(); err == nil {
+			t.Errorf("Expected %v to be invalid", p)
+		}
+	}
+}
+
+func TestConfigProcessors(t *testing.T) {
+	dot, err := ParseConfig([]byte(`{"a": 1, "Postprocess": [{"type": "trim"}, {"type": "header", "text": "// x"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	processors, err := ConfigProcessors(dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(processors) != 2 || processors[1].Text != "// x" {
+		t.Errorf("Unexpected processors %v", processors)
+	}
+	if _, ok := dot.(map[string]interface{})[ProcessKey]; ok {
+		t.Error("Expected the processors to be removed from the configuration")
+	}
+	if all := OutputProcessors(processors, []Processor{{Type: "trim"}, {Type: "build-tag", Expr: "x"}}); len(all) != 3 {
+		t.Errorf("Expected the duplicate to be left out, got %v", all)
+	}
+
+	dot, _ = ParseConfig([]byte(`{"Postprocess": [{"type": "bad"}]}`))
+	if _, err = ConfigProcessors(dot); err == nil || !strings.Contains(err.Error(), "Postprocess 1") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package gengen
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Markers of protected regions. A template marks a protected region by placing each marker on its own line,
+// normally in a comment, like this:
+//
+//	// gengen:begin custom-methods
+//	// gengen:end
+//
+// Whatever is written between the markers in the output file is kept when the file is generated again.
+const (
+	regionBegin = "gengen:begin"
+	regionEnd   = "gengen:end"
+)
+
+// A region is a protected region. start and end are the offsets of its content, which is everything between
+// the line holding the begin marker and the line holding the end marker.
+type region struct {
+	name       string
+	start, end int
+}
+
+// findRegions returns the protected regions in text, in order.
+func findRegions(text []byte) (regions []region, err error) {
+	var cur *region
+	var lineNum int
+	for offset := 0; offset < len(text); {
+		lineNum++
+		line := text[offset:]
+		next := len(text)
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+			next = offset + i + 1
+		}
+
+		if i := bytes.Index(line, []byte(regionBegin)); i >= 0 {
+			if cur != nil {
+				return nil, fmt.Errorf("line %d: protected region %q begins before region %q ends", lineNum, regionName(line[i+len(regionBegin):]), cur.name)
+			}
+			name := regionName(line[i+len(regionBegin):])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: protected region has no name", lineNum)
+			}
+			for _, r := range regions {
+				if r.name == name {
+					return nil, fmt.Errorf("line %d: there is more than one protected region named %q", lineNum, name)
+				}
+			}
+			cur = &region{name: name, start: next}
+		} else if bytes.Contains(line, []byte(regionEnd)) {
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: end of a protected region that was not begun", lineNum)
+			}
+			cur.end = offset
+			regions = append(regions, *cur)
+			cur = nil
+		}
+		offset = next
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("protected region %q does not end", cur.name)
+	}
+	return
+}
+
+// regionName returns the name that follows a begin marker, without any comment terminator after it.
+func regionName(s []byte) string {
+	fields := bytes.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	name := bytes.TrimSuffix(bytes.TrimSuffix(fields[0], []byte("*/")), []byte("-->"))
+	return string(name)
+}
+
+// KeepRegions returns out, a newly generated output, with the content of each of its protected regions replaced by
+// the content of the region with the same name in old, the previous version of the output. Regions of old that
+// are no longer in out are returned in lost, mapped to the content they had.
+func KeepRegions(out, old []byte) (result []byte, lost map[string]string, err error) {
+	outRegions, err := findRegions(out)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generated output: %w", err)
+	}
+	oldRegions, err := findRegions(old)
+	if err != nil {
+		return nil, nil, fmt.Errorf("existing output: %w", err)
+	}
+
+	content := make(map[string][]byte, len(oldRegions))
+	for _, r := range oldRegions {
+		content[r.name] = old[r.start:r.end]
+	}
+
+	var buf bytes.Buffer
+	var offset int
+	for _, r := range outRegions {
+		c, ok := content[r.name]
+		if !ok {
+			continue // a new region keeps what the template gave it
+		}
+		buf.Write(out[offset:r.start])
+		buf.Write(c)
+		offset = r.end
+		delete(content, r.name)
+	}
+	buf.Write(out[offset:])
+
+	for name, c := range content {
+		if lost == nil {
+			lost = make(map[string]string)
+		}
+		lost[name] = string(c)
+	}
+	return buf.Bytes(), lost, nil
+}
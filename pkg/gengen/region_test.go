@@ -0,0 +1,34 @@
+package gengen
+
+import (
+	"testing"
+)
+
+func TestKeepRegions(t *testing.T) {
+	out := "a\n// gengen:begin one\n// default\n// gengen:end\nb\n/* gengen:begin two */\n/* gengen:end */\n"
+	old := "A\n// gengen:begin one\nfunc F() {}\n// gengen:end\n// gengen:begin three\nx\n// gengen:end\n"
+
+	result, lost, err := KeepRegions([]byte(out), []byte(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "a\n// gengen:begin one\nfunc F() {}\n// gengen:end\nb\n/* gengen:begin two */\n/* gengen:end */\n"
+	if string(result) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(result))
+	}
+	if len(lost) != 1 || lost["three"] != "x\n" {
+		t.Errorf("Unexpected lost regions %v", lost)
+	}
+
+	for _, bad := range []string{
+		"// gengen:begin one\n",
+		"// gengen:end\n",
+		"// gengen:begin one\n// gengen:begin two\n// gengen:end\n",
+		"// gengen:begin one\n// gengen:end\n// gengen:begin one\n// gengen:end\n",
+		"// gengen:begin\n// gengen:end\n",
+	} {
+		if _, _, err = KeepRegions([]byte(bad), nil); err == nil {
+			t.Errorf("Expected an error from %q", bad)
+		}
+	}
+}
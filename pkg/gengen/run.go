@@ -0,0 +1,75 @@
+package gengen
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A Prepared job has its template parsed and its dot context loaded, ready to be executed. The command line,
+// the playground and CompareTemplates all run jobs this way, so that they produce the same output.
+type Prepared struct {
+	Job
+	// Parsed is the parsed template of the job.
+	Parsed *Template
+	Dot    interface{}
+	// Processors are the processors of the output of the job, those of its config followed by those of the job.
+	Processors []Processor
+}
+
+// Prepare prepares the job to be executed with dot, its loaded config, and text, the text of its template.
+// It takes the processors out of dot, binds the data files of the job to it, parses the template with opts,
+// using the delimiters of the job if it has them, and applies the params of the template to dot.
+func (j Job) Prepare(dot interface{}, text string, opts Options) (*Prepared, error) {
+	processors, err := ConfigProcessors(dot)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", j.Config, err)
+	}
+	for name, path := range j.Data {
+		value, err := LoadData(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = BindData(dot, name, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", j.Config, err)
+		}
+	}
+	if j.Delims != "" {
+		opts.Delims = j.Delims
+	}
+	tmpl, err := ParseTemplateOptions(j.Template, text, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = tmpl.ApplyParams(dot); err != nil {
+		return nil, err
+	}
+	return &Prepared{Job: j, Parsed: tmpl, Dot: dot, Processors: append(processors, j.Postprocess...)}, nil
+}
+
+// Execute executes the template of the job, writing its output to w. If shared is true, the sections produced
+// with the shared function are returned instead of being put in the output.
+func (p *Prepared) Execute(w io.Writer, shared bool) ([]Section, error) {
+	if shared {
+		return p.Parsed.ExecuteShared(w, p.Dot)
+	}
+	return nil, p.Parsed.Execute(w, p.Dot)
+}
+
+// Assemble returns the output that is written to path, given the outputs of the jobs that produce it and
+// their processors. The outputs of go files are combined with Combine, and others are joined. The result
+// is then run through the processors. path is empty if the output is not written to a file.
+func Assemble(path string, parts []Part, processors []Processor) ([]byte, error) {
+	var out []byte
+	if strings.HasSuffix(path, ".go") {
+		var err error
+		if out, err = Combine(parts); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	} else {
+		for _, part := range parts {
+			out = append(out, part.Out...)
+		}
+	}
+	return Process(path, out, processors)
+}
@@ -0,0 +1,31 @@
+package gengen
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrepare(t *testing.T) {
+	dot, err := ParseConfig([]byte(`{"Name": "a", "Postprocess": [{"type": "header", "text": "// License\n"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := Job{Template: "a.tmpl", Config: "a.json", Postprocess: []Processor{{Type: "trim"}}}
+	p, err := job.Prepare(dot, "package {{.Name}}  \n{{if .Postprocess}}var Processors = 1\n{{end}}", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = p.Execute(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Assemble("", []Part{{Name: job.Template, Out: buf.Bytes()}}, p.Processors)
+	if want := "// License\n\npackage a\n"; err != nil || string(out) != want {
+		t.Errorf("Expected %q, got %q %v", want, out, err)
+	}
+
+	dot, _ = ParseConfig([]byte(`{"Postprocess": [{"type": "teleport"}]}`))
+	if _, err = job.Prepare(dot, "", Options{}); err == nil || err.Error() != `a.json: Postprocess 1: unknown processor type "teleport"` {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// A ScaffoldKind is a kind of collection that a Scaffold can make.
+type ScaffoldKind struct {
+	// Template refers to the template of the Library that produces the collection.
+	Template string
+	// Suffix ends the default name of the type of the collection, after the names of its key and value types.
+	Suffix string
+	// Test refers to the template of the Library that produces a test of the collection.
+	Test string
+}
+
+// ScaffoldKinds are the kinds of collection that a Scaffold can make, by name.
+var ScaffoldKinds = map[string]ScaffoldKind{
+	"map":      {"lib:maps/standard_map", "Map", "lib:maps/map_test"},
+	"slicemap": {"lib:maps/slice_map", "SliceMap", "lib:maps/map_test"},
+}
+
+// A Scaffold starts a new collection, like a map with a particular key and value type, in a package. It writes
+// a configuration of a template of the Library for the collection and one for its test, and the go:generate
+// lines that generate them.
+type Scaffold struct {
+	// Kind is the name of one of the ScaffoldKinds.
+	Kind string
+	// Dir is the directory of the package, which is created if it does not exist.
+	Dir string
+	// Key and Val are the go types of the keys and values, like "string" and "*User". They can be the types of
+	// the package and built-in types.
+	Key, Val string
+	// Safe is true for a collection that is synchronized with a sync.RWMutex.
+	Safe bool
+	// TypeName is the name of the type of the collection. If it is empty, the template names it after the key
+	// and value types, like SafeUserSliceMap.
+	TypeName string
+	// GoFile is the name of the file in Dir that has the go:generate lines. It is "gengen.go" if it is empty,
+	// and the lines are added to it if it already exists.
+	GoFile string
+}
+
+// Name returns the name of the type of the collection.
+func (s Scaffold) Name() (string, error) {
+	if s.TypeName != "" {
+		return s.TypeName, nil
+	}
+	kind, ok := ScaffoldKinds[s.Kind]
+	if !ok {
+		return "", fmt.Errorf("unknown kind of collection %q", s.Kind)
+	}
+	key, val, err := s.names()
+	if err != nil {
+		return "", err
+	}
+	name := key + val + kind.Suffix
+	if s.Safe {
+		name = "Safe" + name
+	}
+	return name, nil
+}
+
+// names returns the CamelCase names of the key and value types, which are empty for a string key and an
+// interface{} value, as the templates expect.
+func (s Scaffold) names() (key, val string, err error) {
+	if key, err = typeName(s.Key); err != nil {
+		return
+	}
+	if val, err = typeName(s.Val); err != nil {
+		return
+	}
+	if key == "String" {
+		key = ""
+	}
+	if val == "Interface" {
+		val = ""
+	}
+	return
+}
+
+// typeName returns a CamelCase name for a go type, like "User" for "*User" and "IntSlice" for "[]int".
+func typeName(expr string) (string, error) {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a go type: %w", expr, err)
+	}
+	var name func(e ast.Expr) string
+	name = func(e ast.Expr) string {
+		switch e := e.(type) {
+		case *ast.Ident:
+			if e.Name == "any" {
+				return "Interface"
+			}
+			return funcs["ucFirst"].(func(string) string)(e.Name)
+		case *ast.StarExpr:
+			return name(e.X)
+		case *ast.SelectorExpr:
+			return e.Sel.Name
+		case *ast.ArrayType:
+			if n := name(e.Elt); n != "" && e.Len == nil {
+				return n + "Slice"
+			} else if n != "" {
+				return n + "Array"
+			}
+		case *ast.MapType:
+			if k, v := name(e.Key), name(e.Value); k != "" && v != "" {
+				return k + v + "Map"
+			}
+		case *ast.InterfaceType:
+			return "Interface"
+		}
+		return ""
+	}
+	if n := name(e); n != "" {
+		return n, nil
+	}
+	return "", fmt.Errorf("cannot name the type %q; give the name of the collection instead", expr)
+}
+
+var wordRE = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+// fileName returns the name of the files of the collection, without an extension, like "safe_user_slice_map".
+func fileName(typeName string) string {
+	return strings.ToLower(wordRE.ReplaceAllString(typeName, "${1}_${2}"))
+}
+
+// Config returns the configuration of the template of the collection. It type checks the key and value types
+// in the package to find the settings that depend on them, like whether the values can be sorted, and leaves
+// out the settings that the template does not have.
+func (s Scaffold) Config() (map[string]interface{}, error) {
+	kind, ok := ScaffoldKinds[s.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind of collection %q", s.Kind)
+	}
+	path, err := ResolveTemplate(kind.Template, "")
+	if err != nil {
+		return nil, err
+	}
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fm, _, err := readFrontMatter(string(text), "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	pkg, key, val, err := s.check()
+	if err != nil {
+		return nil, err
+	}
+	if !types.Comparable(key) {
+		return nil, fmt.Errorf("the key type %s cannot be the key of a map, since it cannot be compared with ==", s.Key)
+	}
+	keyName, valName, err := s.names()
+	if err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{
+		"package":           pkg,
+		"keytype":           s.Key,
+		"valtype":           s.Val,
+		"KeyType":           keyName,
+		"ValType":           valName,
+		"Safe":              "",
+		"TypeName":          s.TypeName,
+		"mapi":              true,
+		"keyIsCopier":       isCopier(key),
+		"valueIsCopier":     isCopier(val),
+		"valueIsComparable": isOrdered(val),
+		"valueIsCopyable":   isBasic(val),
+		"valueIsInterface":  types.IsInterface(val),
+	}
+	if s.Safe {
+		config["Safe"] = "Safe"
+	}
+	if s.TypeName == "" {
+		delete(config, "TypeName")
+	}
+	for name := range config {
+		if _, ok := fm.Params[name]; !ok {
+			delete(config, name)
+		}
+	}
+	return config, nil
+}
+
+// isCopier returns true if t has a Copy method that returns a copy of it.
+func isCopier(t types.Type) bool {
+	sel := types.NewMethodSet(t).Lookup(nil, "Copy")
+	if sel == nil {
+		return false
+	}
+	sig := sel.Type().(*types.Signature)
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), t)
+}
+
+// isOrdered returns true if values of t can be compared with <.
+func isOrdered(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsOrdered != 0
+}
+
+// isBasic returns true if t is a basic type, whose values are copied by assigning them.
+func isBasic(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() != types.UnsafePointer
+}
+
+// check type checks the key and value types in the package, and returns the name of the package and the types.
+// The files of the package that gengen generated are left out, since they may be out of date.
+func (s Scaffold) check() (pkgName string, key, val types.Type, err error) {
+	for _, expr := range []string{s.Key, s.Val} {
+		if strings.Contains(expr, ".") {
+			return "", nil, nil, fmt.Errorf("the type %s is from another package, which the templates cannot import; declare an alias of it in the package, like type X = %s, and use that", expr, expr)
+		}
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	paths, _ := filepath.Glob(filepath.Join(s.Dir, "*.go"))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		if strings.HasSuffix(path, "_test.go") || IsGenerated(data) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, data, 0)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		files = append(files, f)
+	}
+	if len(files) > 0 {
+		pkgName = files[0].Name.Name
+	} else {
+		pkgName = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return '_'
+		}, filepath.Base(s.Dir))
+	}
+
+	src := fmt.Sprintf("package %s\n\nvar gengenKey %s\nvar gengenVal %s\n", pkgName, s.Key, s.Val)
+	f, err := parser.ParseFile(fset, "gengen_scaffold.go", src, 0)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("%s or %s is not a go type", s.Key, s.Val)
+	}
+	files = append(files, f)
+
+	// errors in the rest of the package are ignored, so that a package that does not build yet can be scaffolded
+	var scaffoldErr error
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil), Error: func(err error) {
+		if te, ok := err.(types.Error); ok && te.Fset.Position(te.Pos).Filename == "gengen_scaffold.go" && scaffoldErr == nil {
+			scaffoldErr = errors.New(te.Msg)
+		}
+	}}
+	pkg, _ := conf.Check(pkgName, fset, files, nil)
+	if scaffoldErr != nil {
+		return "", nil, nil, scaffoldErr
+	}
+	return pkgName, pkg.Scope().Lookup("gengenKey").Type(), pkg.Scope().Lookup("gengenVal").Type(), nil
+}
+
+// Write writes the configurations of the collection and its test to json files in the directory of the package,
+// and the go:generate lines that generate them to GoFile, and returns the arguments of gengen on each line,
+// the collection first. It does not replace the files of a collection that already exist.
+func (s Scaffold) Write() ([][]string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+	config, err := s.Config()
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.Name()
+	if err != nil {
+		return nil, err
+	}
+	base := fileName(name)
+	for _, f := range []string{base + ".json", base + ".go", base + "_test.json", base + "_test.go"} {
+		if _, err = os.Stat(filepath.Join(s.Dir, f)); err == nil {
+			return nil, fmt.Errorf("%s already exists", filepath.Join(s.Dir, f))
+		}
+	}
+
+	kind := ScaffoldKinds[s.Kind]
+	testConfig := map[string]interface{}{
+		"package":  config["package"],
+		"TypeName": name,
+		"keytype":  s.Key,
+		"valtype":  s.Val,
+	}
+	// the comments cannot have the types, since a configuration starts at its first open bracket, as in interface{}
+	files := []struct {
+		base, template, comment string
+		config                  map[string]interface{}
+	}{
+		{base, kind.Template, fmt.Sprintf("This config file sets up %s, a %s made with gengen init.", name, s.Kind), config},
+		{base + "_test", kind.Test, fmt.Sprintf("This config file sets up the test of %s, a %s made with gengen init.", name, s.Kind), testConfig},
+	}
+	var lines []string
+	var allArgs [][]string
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.config, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		text := append([]byte("/*\n"+f.comment+"\n*/\n"), append(data, '\n')...)
+		if err = os.WriteFile(filepath.Join(s.Dir, f.base+".json"), text, 0644); err != nil {
+			return nil, err
+		}
+		args := []string{"-c", f.base + ".json", "-o", f.base + ".go", f.template}
+		lines = append(lines, "//go:generate gengen "+strings.Join(args, " ")+"\n")
+		allArgs = append(allArgs, args)
+	}
+
+	goFile := s.GoFile
+	if goFile == "" {
+		goFile = "gengen.go"
+	}
+	goFile = filepath.Join(s.Dir, goFile)
+	text, err := os.ReadFile(goFile)
+	if os.IsNotExist(err) {
+		text = []byte(fmt.Sprintf("package %s\n", config["package"]))
+	} else if err != nil {
+		return nil, err
+	}
+	if block := strings.Join(lines, ""); !bytes.Contains(text, []byte(block)) {
+		text = append(bytes.TrimRight(text, "\n"), "\n\n"+block...)
+		if err = os.WriteFile(goFile, text, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return allArgs, nil
+}
@@ -0,0 +1,90 @@
+package gengen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTypeName(t *testing.T) {
+	for expr, want := range map[string]string{"*User": "User", "[]int": "IntSlice", "map[string]bool": "StringBoolMap", "interface{}": "Interface", "[2]float64": "Float64Array"} {
+		if n, err := typeName(expr); err != nil || n != want {
+			t.Errorf("Expected %q to be named %q, got %q %v", expr, want, n, err)
+		}
+	}
+	if _, err := typeName("func()"); err == nil {
+		t.Error("Expected an error naming a func type")
+	}
+	if n := fileName("SafeUserSliceMap"); n != "safe_user_slice_map" {
+		t.Errorf("Unexpected file name %q", n)
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("user.go", "package users\n\ntype User struct{ Name string }\n\nfunc (u *User) Copy() *User { c := *u; return &c }\n\ntype Score int\n")
+	write("old.go", "// Code generated by gengen. DO NOT EDIT.\n\npackage users\n\nvar x = undefined\n")
+
+	s := Scaffold{Kind: "slicemap", Dir: dir, Key: "string", Val: "*User", Safe: true}
+	config, err := s.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]interface{}{"package": "users", "KeyType": "", "ValType": "User", "Safe": "Safe", "valueIsCopier": true, "valueIsComparable": false} {
+		if config[key] != want {
+			t.Errorf("Expected %s to be %v, got %v", key, want, config[key])
+		}
+	}
+	if _, ok := config["valueIsInterface"]; ok {
+		t.Error("Expected the settings that slice_map does not have to be left out")
+	}
+
+	lines, err := s.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || strings.Join(lines[0], " ") != "-c safe_user_slice_map.json -o safe_user_slice_map.go lib:maps/slice_map" ||
+		strings.Join(lines[1], " ") != "-c safe_user_slice_map_test.json -o safe_user_slice_map_test.go lib:maps/map_test" {
+		t.Errorf("Unexpected args %v", lines)
+	}
+	if _, err = s.Write(); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected the config not to be replaced, got %v", err)
+	}
+	if _, err = (Scaffold{Kind: "map", Dir: dir, Key: "Score", Val: "Score"}).Write(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "gengen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package users\n\n//go:generate gengen -c safe_user_slice_map.json -o safe_user_slice_map.go lib:maps/slice_map\n" +
+		"//go:generate gengen -c safe_user_slice_map_test.json -o safe_user_slice_map_test.go lib:maps/map_test\n\n" +
+		"//go:generate gengen -c score_score_map.json -o score_score_map.go lib:maps/standard_map\n" +
+		"//go:generate gengen -c score_score_map_test.json -o score_score_map_test.go lib:maps/map_test\n"
+	if string(data) != want {
+		t.Errorf("Unexpected go file:\n%s", data)
+	}
+	jobs, err := GenerateJobs(dir)
+	if err != nil || len(jobs) != 4 {
+		t.Fatalf("Unexpected jobs %v %v", jobs, err)
+	}
+	if _, err = jobs[2].Run(); err != nil {
+		t.Error(err)
+	}
+	out, err := jobs[3].Run()
+	if err != nil || !strings.Contains(string(out), "func TestScoreScoreMap(t *testing.T)") || !strings.Contains(string(out), "m := NewScoreScoreMap()") {
+		t.Errorf("Unexpected test of the map %s %v", out, err)
+	}
+
+	for _, bad := range []Scaffold{{Kind: "list", Dir: dir, Key: "string", Val: "string"}, {Kind: "map", Dir: dir, Key: "[]int", Val: "string"}, {Kind: "map", Dir: dir, Key: "string", Val: "Missing"}, {Kind: "map", Dir: dir, Key: "string", Val: "time.Time"}} {
+		if _, err = bad.Config(); err == nil {
+			t.Errorf("Expected an error for %+v", bad)
+		}
+	}
+}
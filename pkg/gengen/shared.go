@@ -0,0 +1,367 @@
+package gengen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// A Section is the output of a call to the shared function.
+type Section struct {
+	// Name is the name of the template that produced the section.
+	Name string
+	Text string
+}
+
+// inline implements the shared function when the template is executed with Execute.
+func (t *Template) inline(name string, data interface{}) (string, error) {
+	text, err := t.section(name, data)
+	if t.out != nil {
+		// the text is counted again when it is written to the output
+		t.out.refund(len(text))
+	}
+	return text, err
+}
+
+// section executes the template that a call to the shared function names, and returns its output.
+func (t *Template) section(name string, data interface{}) (string, error) {
+	if err := t.checkCancelled(); err != nil {
+		return "", err
+	}
+	// the template called counts towards the MaxDepth limit, like a template action
+	if t.limits.MaxDepth > 0 {
+		if t.depth++; t.depth > t.limits.MaxDepth {
+			t.depthErr = fmt.Errorf("shared %q: template calls are nested more than %d deep", name, t.limits.MaxDepth)
+			return "", t.depthErr
+		}
+		defer func() { t.depth-- }()
+	}
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	if t.out != nil {
+		// the section counts towards the MaxOutput limit, like the rest of the output
+		w = t.out.to(&buf)
+	}
+	err := t.ExecuteTemplate(w, name, data)
+	return buf.String(), err
+}
+
+// ExecuteShared executes the template like Execute does, except that the output of calls to the shared
+// function is returned as sections instead of being written to w. It must not be called while the
+// template is executing elsewhere.
+func (t *Template) ExecuteShared(w io.Writer, data interface{}) (sections []Section, err error) {
+	t.Funcs(template.FuncMap{"shared": func(name string, data interface{}) (string, error) {
+		text, err := t.section(name, data)
+		if err == nil {
+			sections = append(sections, Section{name, text})
+		}
+		return "", err
+	}})
+	defer t.Funcs(template.FuncMap{"shared": t.inline})
+	err = t.Execute(w, data)
+	return
+}
+
+const (
+	sharedHeader = "// Code generated by gengen. DO NOT EDIT.\n" +
+		"// Each section of this file is shared by the generated files of the package that are listed in it.\n"
+	sharedBegin = "// gengen:shared "
+	sharedUsers = " used by "
+	sharedEnd   = "// gengen:shared-end"
+)
+
+// A SharedFile holds the sections that templates produce once for all of the go files generated into a
+// package. Sections with the same text are only kept once, no matter how many files use them.
+type SharedFile struct {
+	Package  string
+	Sections []*SharedSection
+}
+
+// A SharedSection is a section of a SharedFile.
+type SharedSection struct {
+	Name string
+	// Body is the text of the section, without its imports.
+	Body    string
+	Imports []Import
+	// Users are the names of the files that use the section.
+	Users []string
+}
+
+// An Import is an import declaration of a go file.
+type Import struct {
+	Name string
+	Path string
+}
+
+// LoadSharedFile reads a shared file. If the file does not exist, an empty one is returned.
+func LoadSharedFile(path string) (*SharedFile, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return new(SharedFile), nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(sharedHeader)) {
+		return nil, fmt.Errorf("%s was not written by gengen, so it cannot be used as a shared file", path)
+	}
+
+	imports, body, pkg, err := splitImports(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f := &SharedFile{Package: pkg}
+	var s *SharedSection
+	var text strings.Builder
+	for _, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, sharedBegin):
+			if s != nil {
+				return nil, fmt.Errorf("%s: section %q is not ended", path, s.Name)
+			}
+			s = new(SharedSection)
+			s.Name = strings.TrimPrefix(trimmed, sharedBegin)
+			if i := strings.Index(s.Name, sharedUsers); i >= 0 {
+				s.Users = strings.Split(s.Name[i+len(sharedUsers):], ", ")
+				s.Name = s.Name[:i]
+			}
+			text.Reset()
+		case trimmed == sharedEnd:
+			if s == nil {
+				return nil, fmt.Errorf("%s: a section is ended that was not begun", path)
+			}
+			s.Body = strings.TrimSpace(text.String())
+			if s.Imports, err = usedImports(s.Body, imports); err != nil {
+				return nil, fmt.Errorf("%s: section %q: %w", path, s.Name, err)
+			}
+			f.Sections = append(f.Sections, s)
+			s = nil
+		case s != nil:
+			text.WriteString(line)
+		}
+	}
+	if s != nil {
+		return nil, fmt.Errorf("%s: section %q is not ended", path, s.Name)
+	}
+	return f, nil
+}
+
+// Update replaces the sections used by the go file named user, whose generated content is out, with
+// sections. Sections no file uses any more are removed.
+func (f *SharedFile) Update(user string, out []byte, sections []Section) error {
+	var kept []*SharedSection
+	for _, s := range f.Sections {
+		users := s.Users[:0]
+		for _, u := range s.Users {
+			if u != user {
+				users = append(users, u)
+			}
+		}
+		if s.Users = users; len(users) > 0 {
+			kept = append(kept, s)
+		}
+	}
+	f.Sections = kept
+	if len(sections) == 0 {
+		return nil
+	}
+
+	_, _, pkg, err := splitImports(out)
+	if err != nil {
+		return fmt.Errorf("%s: %w", user, err)
+	}
+	if f.Package == "" || len(f.Sections) == 0 {
+		f.Package = pkg
+	} else if pkg != f.Package {
+		return fmt.Errorf("%s is in package %s, but the shared file is in package %s", user, pkg, f.Package)
+	}
+
+next:
+	for _, section := range sections {
+		imports, body, _, err := splitImports([]byte("package " + pkg + "\n" + section.Text))
+		if err != nil {
+			return fmt.Errorf("%s: shared section %q: %w", user, section.Name, err)
+		}
+		// format the body by itself so that it can be compared to the ones already in the file
+		src, err := format.Source([]byte("package " + pkg + "\n\n" + body))
+		if err != nil {
+			return fmt.Errorf("%s: shared section %q: %w", user, section.Name, err)
+		}
+		body = strings.TrimSpace(string(src[bytes.IndexByte(src, '\n'):]))
+		if body == "" {
+			continue
+		}
+		for _, s := range f.Sections {
+			if s.Body == body {
+				if !contains(s.Users, user) {
+					s.Users = append(s.Users, user)
+					sort.Strings(s.Users)
+				}
+				continue next
+			}
+		}
+		f.Sections = append(f.Sections, &SharedSection{Name: section.Name, Body: body, Imports: imports, Users: []string{user}})
+	}
+	sort.SliceStable(f.Sections, func(i, j int) bool {
+		return f.Sections[i].Name < f.Sections[j].Name
+	})
+	return nil
+}
+
+// used returns the names, imports and bodies of the sections that the go file named user uses.
+func (f *SharedFile) used(user string) []byte {
+	var buf bytes.Buffer
+	for _, s := range f.Sections {
+		if contains(s.Users, user) {
+			fmt.Fprintf(&buf, "%s\n%v\n%s\n", s.Name, s.Imports, s.Body)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Bytes returns the content of the shared file, or nil if it has no sections.
+func (f *SharedFile) Bytes() ([]byte, error) {
+	if len(f.Sections) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString(sharedHeader)
+	buf.WriteString("\npackage " + f.Package + "\n")
+	var imports []Import
+	for _, s := range f.Sections {
+		imports = append(imports, s.Imports...)
+	}
+	writeImports(&buf, imports)
+	for _, s := range f.Sections {
+		fmt.Fprintf(&buf, "\n%s%s%s%s\n%s\n%s\n", sharedBegin, s.Name, sharedUsers, strings.Join(s.Users, ", "), s.Body, sharedEnd)
+	}
+	return format.Source(buf.Bytes())
+}
+
+// splitImports separates the source of a go file into its imports and the declarations that follow them.
+func splitImports(src []byte) (imports []Import, body string, pkg string, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return
+	}
+	pkg = f.Name.Name
+	end := fset.Position(f.Name.End()).Offset
+	for _, d := range f.Decls {
+		if g, ok := d.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			end = fset.Position(g.End()).Offset
+		}
+	}
+	for _, spec := range f.Imports {
+		i := Import{}
+		i.Path, _ = strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil {
+			i.Name = spec.Name.Name
+		}
+		imports = append(imports, i)
+	}
+	body = string(src[end:])
+	return
+}
+
+// usedImports returns the imports that the go declarations in body use.
+func usedImports(body string, imports []Import) (used []Import, err error) {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+body, 0)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				names[id.Name] = true
+			}
+		}
+		return true
+	})
+	for _, i := range imports {
+		if i.Name == "_" || i.Name == "." || names[i.localName()] {
+			used = append(used, i)
+		}
+	}
+	return
+}
+
+// localName returns the name a file uses to refer to the import. Without a name in the import, it guesses
+// the name of the package from its path the way goimports does: a major version like v2 at the end is
+// skipped, a go- prefix is dropped, and the name ends before the first character that cannot be in a go
+// name, so that "gopkg.in/yaml.v3" is yaml.
+func (i Import) localName() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	name := path.Base(i.Path)
+	if strings.HasPrefix(name, "v") {
+		if _, err := strconv.Atoi(name[1:]); err == nil && path.Dir(i.Path) != "." {
+			name = path.Base(path.Dir(i.Path))
+		}
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if n := strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); n >= 0 {
+		name = name[:n]
+	}
+	return name
+}
+
+// writeImports writes an import declaration for the imports, leaving out duplicates.
+func writeImports(buf *bytes.Buffer, imports []Import) {
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].Path != imports[j].Path {
+			return imports[i].Path < imports[j].Path
+		}
+		return imports[i].Name < imports[j].Name
+	})
+	var unique []Import
+	for i, imp := range imports {
+		if i == 0 || imp != imports[i-1] {
+			unique = append(unique, imp)
+		}
+	}
+	switch len(unique) {
+	case 0:
+		return
+	case 1:
+		buf.WriteString("\nimport " + unique[0].String() + "\n")
+		return
+	}
+	buf.WriteString("\nimport (\n")
+	for _, imp := range unique {
+		buf.WriteString("\t" + imp.String() + "\n")
+	}
+	buf.WriteString(")\n")
+}
+
+// String returns the import as it appears in an import declaration.
+func (i Import) String() string {
+	if i.Name != "" {
+		return i.Name + " " + strconv.Quote(i.Path)
+	}
+	return strconv.Quote(i.Path)
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package gengen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShared(t *testing.T) {
+	tmpl, err := ParseTemplate("test", `package p
+{{shared "helper" .}}
+type {{.}}Map map[string]int
+{{define "helper"}}
+import "sort"
+
+func sortKeys(k []string) { sort.Strings(k) }
+{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, "A"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "func sortKeys") {
+		t.Errorf("Expected the shared section in place, got:\n%s", buf.String())
+	}
+
+	dir := t.TempDir()
+	shared := filepath.Join(dir, "gengen_shared.go")
+	for _, name := range []string{"A", "B"} {
+		buf.Reset()
+		sections, err := tmpl.ExecuteShared(&buf, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(buf.String(), "sortKeys") {
+			t.Errorf("Expected the shared section to be left out, got:\n%s", buf.String())
+		}
+		f, err := LoadSharedFile(shared)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = f.Update(strings.ToLower(name)+".go", buf.Bytes(), sections); err != nil {
+			t.Fatal(err)
+		}
+		data, err := f.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(shared, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, _ := os.ReadFile(shared)
+	s := string(data)
+	if strings.Count(s, "func sortKeys") != 1 || !strings.Contains(s, `import "sort"`) || !strings.Contains(s, "used by a.go, b.go") {
+		t.Errorf("Unexpected shared file:\n%s", s)
+	}
+
+	// a file that stops using a section no longer keeps it
+	f, err := LoadSharedFile(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Update("a.go", nil, nil)
+	if len(f.Sections) != 1 || len(f.Sections[0].Users) != 1 {
+		t.Errorf("Unexpected sections %v", f.Sections)
+	}
+	f.Update("b.go", nil, nil)
+	if data, _ = f.Bytes(); data != nil {
+		t.Errorf("Expected an empty shared file, got:\n%s", data)
+	}
+}
+
+func TestUsedImports(t *testing.T) {
+	imports := []Import{{Path: "gopkg.in/yaml.v3"}, {Path: "github.com/a/b/v2"}, {Path: "github.com/a/go-cmp"}, {Path: "os"}, {Name: "y", Path: "gopkg.in/yaml.v2"}}
+	used, err := usedImports("func f(v interface{}) ([]byte, error) { b.Check(cmp.Diff); return yaml.Marshal(v) }", imports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Import{imports[0], imports[1], imports[2]}
+	if len(used) != len(want) {
+		t.Fatalf("Expected the imports %v to be used, got %v", want, used)
+	}
+	for i := range want {
+		if used[i] != want[i] {
+			t.Errorf("Expected the imports %v to be used, got %v", want, used)
+		}
+	}
+}
+
+func TestCheckDeclarations(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package p\n\ntype A int\n\nfunc (a A) String() string { return \"\" }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other_test.go"), []byte("package p_test\n\ntype A int\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ok := map[string][]byte{filepath.Join(dir, "b.go"): []byte("package p\n\ntype B int\n\nfunc (b *B) String() string { return \"\" }\n\nfunc init() {}\n")}
+	if err := CheckDeclarations(ok); err != nil {
+		t.Error(err)
+	}
+
+	bad := map[string][]byte{filepath.Join(dir, "b.go"): []byte("package p\n\nvar A, B int\n")}
+	err := CheckDeclarations(bad)
+	if err == nil || !strings.Contains(err.Error(), "b.go:3:5: A is already declared at ") {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	// methods of generic types with the same names belong to their types
+	generic := map[string][]byte{filepath.Join(dir, "c.go"): []byte("package p\n\ntype C[K comparable, V any] map[K]V\n\nfunc (m *C[K, V]) Len() int { return len(*m) }\n\n" +
+		"type D[K comparable, V any] map[K]V\n\nfunc (m D[K, V]) Len() int { return len(m) }\n")}
+	if err := CheckDeclarations(generic); err != nil {
+		t.Error(err)
+	}
+
+	// the file being replaced does not count
+	replaced := map[string][]byte{filepath.Join(dir, "a.go"): []byte("package p\n\ntype A string\n")}
+	if err := CheckDeclarations(replaced); err != nil {
+		t.Error(err)
+	}
+
+	// files that cannot be in the same build can declare the same things
+	hello := "package p\n\nfunc Hello() {}\n"
+	for _, test := range []struct {
+		files map[string]string
+		dup   bool
+	}{
+		{map[string]string{"hello_wasm.go": "//go:build wasm\n\n" + hello, "hello_other.go": "//go:build !wasm\n\n" + hello}, false},
+		{map[string]string{"hello_linux.go": hello, "hello_windows_amd64.go": hello}, false},
+		{map[string]string{"hello_unix.go": "// +build darwin linux\n\n" + hello, "hello_windows.go": hello}, false},
+		{map[string]string{"hello_ios.go": hello, "hello_other.go": "//go:build !darwin\n\n" + hello}, false},
+		{map[string]string{"hello_a.go": "//go:build debug\n\n" + hello, "hello_b.go": "//go:build !debug\n\n" + hello}, false},
+		{map[string]string{"hello_wasm.go": hello, "hello.go": hello}, true},
+		{map[string]string{"hello_linux.go": hello, "hello_other.go": "//go:build unix && amd64\n\n" + hello}, true},
+		{map[string]string{"hello_a.go": "//go:build debug\n\n" + hello, "hello_b.go": "//go:build trace\n\n" + hello}, true},
+	} {
+		files := make(map[string][]byte)
+		var names []string
+		for name, text := range test.files {
+			files[filepath.Join(dir, name)] = []byte(text)
+			names = append(names, name)
+		}
+		if err := CheckDeclarations(files); (err != nil) != test.dup {
+			t.Errorf("Unexpected result for %v: %v", names, err)
+		}
+	}
+}
+
+func TestLintShared(t *testing.T) {
+	tmpl, err := ParseTemplate("test", `{{shared "missing" .}}{{shared "t" .}}{{define "t"}}{{.a}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := Lint(tmpl.Template, map[string][]byte{"config.json": []byte(`{"a": 1}`)})
+	if len(issues) != 1 || issues[0].String() != `test:1:9: template "missing" is not defined` {
+		t.Errorf("Unexpected issues %v", issues)
+	}
+}
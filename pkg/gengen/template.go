@@ -0,0 +1,222 @@
+// Package gengen implements the gengen generator, which executes a go text template using the contents
+// of a json configuration file as its dot context. The gengen command is a thin wrapper around this package.
+package gengen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Template is a parsed template, ready to execute.
+type Template struct {
+	*template.Template
+	// Files are the names of the template files the template was parsed from, starting with the template
+	// itself, followed by the template it extends, if any, and so on.
+	Files []string
+	// FrontMatter holds the settings the template declares.
+	FrontMatter FrontMatter
+	limits      Limits
+	// depth is the number of template calls in progress while the template executes with a MaxDepth limit,
+	// and depthErr is the error when it goes over the limit
+	depth    int
+	depthErr error
+	// out is the writer of the output while the template executes with limits, which the output of calls
+	// to the shared function also counts against
+	out *limitWriter
+	// cancelled is set when an execution takes longer than its timeout, which stops it at its next range
+	// iteration, template call or probe, and running is closed once it stops. monitorMu keeps the monitors
+	// of Instrument from being told about a probe once it is cancelled.
+	cancelled int32
+	running   chan struct{}
+	monitorMu sync.Mutex
+}
+
+// Options change how a template is parsed.
+type Options struct {
+	// Delims are the delimiters of the actions of the template, separated by a space, if its front matter
+	// does not set them. The templates it extends are not affected. The default is "{{ }}".
+	Delims string
+	// Rebase, if not nil, changes the path of each template that the template extends, once it is resolved.
+	// It lets a template be parsed with another copy of the templates it extends, like an older version.
+	Rebase func(path string) string
+	// Restricted limits the templates that the template can extend to those of the Library and those in its own
+	// directory or below it, for templates from untrusted sources. See checkSandboxedRef.
+	Restricted bool
+	// Limits are the limits on executing the template.
+	Limits Limits
+}
+
+// funcs are the functions available to templates in addition to the standard ones.
+var funcs = template.FuncMap{
+	"extends": func(string) (string, error) {
+		return "", errors.New("extends must be the first action in a template")
+	},
+	// lcFirst and ucFirst change the case of the first letter of a string, which is useful for turning
+	// names into unexported or exported go identifiers.
+	"lcFirst": func(s string) string {
+		if s == "" {
+			return s
+		}
+		r, n := utf8.DecodeRuneInString(s)
+		return string(unicode.ToLower(r)) + s[n:]
+	},
+	"ucFirst": func(s string) string {
+		if s == "" {
+			return s
+		}
+		r, n := utf8.DecodeRuneInString(s)
+		return string(unicode.ToUpper(r)) + s[n:]
+	},
+	// shared is replaced by a function bound to the template once it is parsed
+	"shared": func(string, interface{}) (string, error) {
+		return "", errors.New("shared is not available")
+	},
+}
+
+// ParseTemplate parses the text of a template. name identifies the template in errors and reports, and is
+// normally the path of the template file.
+//
+// A template may start with an extends action that names another template, like this:
+//
+//	{{extends "lib:maps/slice_map"}}
+//
+// The named template, which is found using ResolveTemplate relative to the directory of name, becomes the
+// template that executes, and the define statements of the extending template replace the blocks and
+// templates of the same name in it. An extending template may contain nothing else.
+//
+// A template can also start with front matter, as described by FrontMatter.
+//
+// The shared function executes the named template with the given data, like a template action does:
+//
+//	{{shared "interfaces" .}}
+//
+// Executing the template with Execute puts the output in place, and executing it with ExecuteShared
+// collects it as a section to be shared by all the files generated into a package.
+func ParseTemplate(name, text string) (*Template, error) {
+	return ParseTemplateOptions(name, text, Options{})
+}
+
+// ParseTemplateOptions parses the text of a template like ParseTemplate does, using the given options.
+func ParseTemplateOptions(name, text string, opts Options) (*Template, error) {
+	t, err := parseTemplate(name, text, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.Funcs(template.FuncMap{"shared": t.inline})
+	t.limit(opts.Limits)
+	return t, nil
+}
+
+func parseTemplate(name, text string, opts Options, files []string) (*Template, error) {
+	for _, f := range files {
+		if f == name {
+			return nil, fmt.Errorf("%s: templates extend each other in a loop: %s", files[0], strings.Join(append(files, name), " -> "))
+		}
+	}
+	files = append(files, name)
+
+	fm, text, err := readFrontMatter(text, opts.Delims)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	left, right, _ := fm.delims()
+	t, err := template.New(name).Delims(left, right).Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	base, err := extends(t.Tree)
+	if err != nil || base == "" {
+		return &Template{Template: t, Files: files, FrontMatter: fm}, err
+	}
+
+	if opts.Restricted {
+		if err = checkSandboxedRef(base); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	path, err := ResolveTemplate(base, filepath.Dir(name))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if opts.Restricted {
+		if err = checkSandboxedPath(base, path, filepath.Dir(name)); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if opts.Rebase != nil {
+		path = opts.Rebase(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	// the delimiters do not apply to the templates that are extended
+	opts.Delims = ""
+	b, err := parseTemplate(path, string(data), opts, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range t.Templates() {
+		if d.Name() == name {
+			continue
+		}
+		if _, err = b.AddParseTree(d.Name(), d.Tree); err != nil {
+			return nil, err
+		}
+	}
+	// the params of the extending template add to and replace those of the template it extends
+	for key, p := range fm.Params {
+		if b.FrontMatter.Params == nil {
+			b.FrontMatter.Params = make(map[string]Param)
+		}
+		b.FrontMatter.Params[key] = p
+	}
+	return b, nil
+}
+
+// extends returns the name of the template that the template in tree extends, or an empty string if it
+// does not extend one.
+func extends(tree *parse.Tree) (base string, err error) {
+	if tree == nil || tree.Root == nil {
+		return
+	}
+	for _, n := range tree.Root.Nodes {
+		switch n := n.(type) {
+		case *parse.TextNode:
+			if len(strings.TrimSpace(string(n.Text))) == 0 {
+				continue
+			}
+		case *parse.CommentNode:
+			continue
+		case *parse.ActionNode:
+			if base == "" && len(n.Pipe.Decl) == 0 && len(n.Pipe.Cmds) == 1 {
+				args := n.Pipe.Cmds[0].Args
+				if id, ok := args[0].(*parse.IdentifierNode); ok && id.Ident == "extends" {
+					if len(args) != 2 {
+						return "", fmt.Errorf("%s: extends takes the name of one template", tree.ParseName)
+					}
+					s, ok := args[1].(*parse.StringNode)
+					if !ok {
+						return "", fmt.Errorf("%s: extends takes the name of one template", tree.ParseName)
+					}
+					base = s.Text
+					continue
+				}
+			}
+		}
+		if base != "" {
+			loc, _ := tree.ErrorContext(n)
+			return "", fmt.Errorf("%s: a template that extends another can only contain define statements", loc)
+		}
+		return "", nil // extends, if present later, will fail when executed
+	}
+	return
+}
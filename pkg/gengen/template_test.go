@@ -0,0 +1,206 @@
+package gengen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtends(t *testing.T) {
+	dir := t.TempDir()
+	base := `<{{block "a" .}}A{{end}}{{block "b" .}}B{{end}}>`
+	if err := os.WriteFile(filepath.Join(dir, "base.tmpl"), []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "middle.tmpl"), []byte(`{{extends "base.tmpl"}}{{define "a"}}a{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := ParseTemplate(filepath.Join(dir, "derived.tmpl"), "{{/* comment */}}\n{{extends \"middle.tmpl\"}}\n{{define \"b\"}}{{.}}{{end}}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "<ab>" {
+		t.Errorf("Expected <ab>, got %s", buf.String())
+	}
+	if len(tmpl.Files) != 3 || tmpl.Files[2] != filepath.Join(dir, "base.tmpl") {
+		t.Errorf("Unexpected files %v", tmpl.Files)
+	}
+
+	for _, bad := range []string{
+		`{{extends "base.tmpl"}}text`,
+		`{{extends "derived.tmpl"}}`,
+		`{{extends "missing.tmpl"}}`,
+		`{{extends}}`,
+	} {
+		if _, err = ParseTemplate(filepath.Join(dir, "derived.tmpl"), bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestCaseFuncs(t *testing.T) {
+	tmpl, err := ParseTemplate("case", `{{lcFirst "StringMap"}} {{ucFirst "stringMap"}} [{{lcFirst ""}}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "stringMap StringMap []" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
+
+func TestDelims(t *testing.T) {
+	tests := []struct {
+		text, delims, expected string
+	}{
+		{"[[/*gengen {\"delims\": \"[[ ]]\"} */ -]]\n{{x}} [[.]]", "", "{{x}} a"},
+		{"{{/*gengen\n{\"delims\": \"[[ ]]\"}\n*/}}[[.]]", "<< >>", "a"},
+		{"<<.>> {{.}}", "<< >>", "a {{.}}"},
+		{"{{/*gengen */}}{{.}}", "", "a"},
+	}
+	for _, test := range tests {
+		tmpl, err := ParseTemplateOptions("test", test.text, Options{Delims: test.delims})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, "a"); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, buf.String())
+		}
+	}
+
+	// line numbers are kept
+	_, err := ParseTemplate("test", "{{/*gengen\n{\"delims\": \"[[ ]]\"}\n*/}}\n[[if]]")
+	if err == nil || !strings.Contains(err.Error(), "test:4:") {
+		t.Errorf("Unexpected error %v", err)
+	}
+	for _, bad := range []string{
+		`{{/*gengen {"delim": "[[ ]]"} */}}`,
+		`{{/*gengen {"delims": "[["} */}}`,
+		`[[/*gengen {} */]]`,
+	} {
+		if _, err = ParseTemplate("test", bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestRequires(t *testing.T) {
+	for _, ok := range []string{
+		`{{/*gengen {"requires": "0.1"} */}}`,
+		`{{/*gengen {"requires": "v` + Version + `", "features": ["extends", "params"]} */}}`,
+	} {
+		if _, err := ParseTemplate("test", ok); err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", ok, err)
+		}
+	}
+	tests := map[string]string{
+		`{{/*gengen {"requires": "99.0.0", "newer": true} */}}`: "requires gengen 99.0.0 or later",
+		`{{/*gengen {"features": ["extends", "teleport"]} */}}`: `features that gengen ` + Version + ` does not have: "teleport"`,
+		`{{/*gengen {"requires": "one"} */}}`:                   `bad version "one"`,
+	}
+	for text, want := range tests {
+		if _, err := ParseTemplate("test", text); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected an error with %q parsing %q, got %v", want, text, err)
+		}
+	}
+}
+
+func TestExtendsLibrary(t *testing.T) {
+	// the templates built into gengen are the ones used when the project does not require the gengen module
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir, err := extractLibrary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded, err := os.ReadFile(filepath.Join(dir, "map_src", "slice_map.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := os.ReadFile(filepath.Join("..", "..", "templates", "map_src", "slice_map.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(embedded, orig) {
+		t.Error("Expected the embedded template to be the same as the one in the module")
+	}
+	if dir2, err := extractLibrary(); err != nil || dir2 != dir {
+		t.Errorf("Expected the templates to be extracted to the same directory, got %s, %v", dir2, err)
+	}
+	if _, err = ResolveTemplate("lib:maps/../../x", ""); err == nil {
+		t.Error("Expected a reference outside of the collection to fail")
+	}
+
+	// the map refers to the MapI interface, which mapi includes in the output
+	out := checkGo(t, `{{extends "lib:maps/slice_map"}}
+{{define "String"}}
+func (o *{{template "TypeName" .}}) String() string {
+	return fmt.Sprintf("my map of %d", o.Len())
+}
+{{end}}`, `{"package": "my", "keytype": "string", "valtype": "string", "ValType": "String", "mapi": true}`)
+	if !strings.Contains(out, `"my map of %d"`) {
+		t.Error("Expected the String block to be replaced")
+	}
+}
+
+func TestUnexportedNames(t *testing.T) {
+	// the default names of a map of strings to interface{} values, which are all blank
+	config := `{"package": "my", "keytype": "string", "valtype": "interface{}", "exported": false, "mapi": true}`
+	for _, kind := range []string{"standard_map", "slice_map"} {
+		t.Run(kind, func(t *testing.T) {
+			out := checkGo(t, `{{extends "lib:maps/`+kind+`"}}`, config)
+			if !strings.Contains(out, "type mapI interface") || strings.Contains(out, "func New") {
+				t.Error("Expected the names to be unexported")
+			}
+		})
+	}
+}
+
+// checkGo executes a template with the config, and type checks the go file it produces, which it returns.
+func checkGo(t *testing.T, text, config string) string {
+	t.Helper()
+	tmpl, err := ParseTemplate(filepath.Join(t.TempDir(), "test.tmpl"), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot, err := ParseConfig([]byte(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tmpl.ApplyParams(dot); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, dot); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "test.go", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err = conf.Check(f.Name.Name, fset, []*ast.File{f}, nil); err != nil {
+		t.Error(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,289 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A TemplateDoc documents a template, for people choosing a template and writing its configuration.
+type TemplateDoc struct {
+	// Name is how the template is referred to, like "lib:maps/slice_map".
+	Name string
+	Path string
+	// Description is the first paragraph of the comment at the start of the template, after its front matter.
+	Description string
+	// Params are the params of the template, the required ones first, and then by name.
+	Params []ParamDoc
+	// Requires and Features are the requirements of the template, from its front matter.
+	Requires string
+	Features []string
+	// ExampleName is the name of the configuration file the example comes from, if it comes from one that a
+	// go:generate line in the directory of the template uses with it. Otherwise the example is made from the
+	// params.
+	ExampleName string
+	// Example is an example configuration of the template.
+	Example string
+	// Sample is the start of the output of the template with the example configuration, or the error that
+	// stopped it.
+	Sample string
+	// SampleLang is the language of the sample, like "go", for highlighting.
+	SampleLang string
+}
+
+// A ParamDoc is a param of a template, with its name.
+type ParamDoc struct {
+	Name string
+	Param
+}
+
+// DefaultText returns the default of the param as it is written in json, or an empty string if it has none.
+func (p ParamDoc) DefaultText() string {
+	if p.Default == nil {
+		return ""
+	}
+	data, err := json.Marshal(p.Default)
+	if err != nil {
+		return fmt.Sprint(p.Default)
+	}
+	return string(data)
+}
+
+// DocumentTemplate documents the template file at path, which is referred to as name. The sample output is
+// cut to sampleLines lines.
+func DocumentTemplate(name, path string, sampleLines int) (*TemplateDoc, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := ParseTemplate(path, string(text))
+	if err != nil {
+		return nil, err
+	}
+	fm := tmpl.FrontMatter
+	d := &TemplateDoc{Name: name, Path: path, Requires: fm.Requires, Features: fm.Features, Description: description(string(text))}
+	for key, p := range fm.Params {
+		d.Params = append(d.Params, ParamDoc{key, p})
+	}
+	sort.Slice(d.Params, func(i, j int) bool {
+		if d.Params[i].Required != d.Params[j].Required {
+			return d.Params[i].Required
+		}
+		return d.Params[i].Name < d.Params[j].Name
+	})
+
+	var out []byte
+	if job, ok := exampleJob(path); ok {
+		data, err := os.ReadFile(job.Config)
+		if err != nil {
+			return nil, err
+		}
+		d.ExampleName, d.Example = filepath.Base(job.Config), strings.TrimSpace(string(data))
+		out, err = job.Run()
+		if err != nil {
+			out = []byte(err.Error())
+		}
+		d.SampleLang = strings.TrimPrefix(filepath.Ext(job.Output), ".")
+	} else {
+		dot := make(map[string]interface{})
+		for _, p := range d.Params {
+			if v := p.example(); v != nil {
+				dot[p.Name] = v
+			}
+		}
+		data, _ := json.MarshalIndent(dot, "", "  ")
+		d.Example = string(data)
+		var buf bytes.Buffer
+		if err = tmpl.ApplyParams(dot); err == nil {
+			err = tmpl.Execute(&buf, dot)
+		}
+		out = buf.Bytes()
+		if err != nil {
+			out = []byte(err.Error())
+		} else if bytes.HasPrefix(bytes.TrimLeft(out, "\n"), []byte("package ")) || bytes.Contains(out, []byte("\npackage ")) {
+			d.SampleLang = "go"
+		}
+	}
+	lines := strings.SplitAfter(strings.TrimRight(string(out), "\n"), "\n")
+	if sampleLines > 0 && len(lines) > sampleLines {
+		lines = append(lines[:sampleLines], "...\n")
+	}
+	d.Sample = strings.Join(lines, "")
+	return d, nil
+}
+
+// example returns a value of the param for an example configuration, which is its default, or a
+// placeholder if it is required. It returns nil if the param can be left out.
+func (p ParamDoc) example() interface{} {
+	if !p.Required {
+		return p.Default
+	}
+	switch p.Type {
+	case "bool":
+		return true
+	case "int", "uint", "float", "number":
+		return 1
+	case "list":
+		return []interface{}{}
+	case "object":
+		return map[string]interface{}{}
+	case "duration":
+		return "1s"
+	case "time":
+		return "2006-01-02T15:04:05Z"
+	case "date":
+		return "2006-01-02"
+	}
+	return p.Name
+}
+
+// exampleJob returns the first job of the go:generate lines in the directory of the template at path that
+// uses the template.
+func exampleJob(path string) (Job, bool) {
+	jobs, _ := GenerateJobs(filepath.Dir(path))
+	for _, job := range jobs {
+		if job.Template == path {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
+
+// commentRE matches a comment at the start of a template, written with the standard delimiters.
+var commentRE = regexp.MustCompile(`^\s*\{\{-?\s*/\*((?s).*?)\*/\s*-?\}\}`)
+
+// description returns the first paragraph of the first comment at the start of the text of a template,
+// after its front matter.
+func description(text string) string {
+	if fm, rest, err := readFrontMatter(text, ""); err == nil && fm.Delims == "" {
+		text = rest
+	}
+	for {
+		m := commentRE.FindStringSubmatch(text)
+		if m == nil {
+			return ""
+		}
+		text = text[len(m[0]):]
+		if c := strings.TrimSpace(m[1]); c != "" {
+			return strings.Join(strings.Fields(strings.SplitN(c, "\n\n", 2)[0]), " ")
+		}
+	}
+}
+
+// WriteMarkdown writes the documentation of templates as a Markdown document with the given title.
+func WriteMarkdown(w io.Writer, title string, docs []*TemplateDoc) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	for _, d := range docs {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", d.Name, anchor(d.Name))
+	}
+	for _, d := range docs {
+		fmt.Fprintf(&b, "\n## %s\n\n", d.Name)
+		if d.Description != "" {
+			b.WriteString(d.Description + "\n\n")
+		}
+		if d.Requires != "" || len(d.Features) > 0 {
+			b.WriteString("Requires gengen " + d.Requires)
+			if len(d.Features) > 0 {
+				b.WriteString(" with the features " + strings.Join(d.Features, ", "))
+			}
+			b.WriteString(".\n\n")
+		}
+		if len(d.Params) > 0 {
+			b.WriteString("| Param | Type | Default | Description |\n|---|---|---|---|\n")
+			for _, p := range d.Params {
+				def := "`" + p.DefaultText() + "`"
+				if p.Required {
+					def = "required"
+				} else if p.Default == nil {
+					def = ""
+				}
+				fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", p.Name, p.Type, def, strings.ReplaceAll(p.Doc, "|", `\|`))
+			}
+			b.WriteString("\n")
+		}
+		if d.ExampleName != "" {
+			fmt.Fprintf(&b, "Example config, %s:\n\n", d.ExampleName)
+		} else {
+			b.WriteString("Example config:\n\n")
+		}
+		b.WriteString(fence(d.Example, "json") + "\nSample output:\n\n" + fence(d.Sample, d.SampleLang))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fence returns text as a fenced block of code, with a fence longer than any run of backquotes in it.
+func fence(text, lang string) string {
+	f := "```"
+	for strings.Contains(text, f) {
+		f += "`"
+	}
+	return f + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + f + "\n"
+}
+
+var anchorRE = regexp.MustCompile(`[^a-z0-9_ -]+`)
+
+// anchor returns the anchor that Markdown renderers give to a heading.
+func anchor(heading string) string {
+	return strings.ReplaceAll(anchorRE.ReplaceAllString(strings.ToLower(heading), ""), " ", "-")
+}
+
+// WriteHTML writes the documentation of templates as an HTML page with the given title.
+func WriteHTML(w io.Writer, title string, docs []*TemplateDoc) error {
+	return docPage.Execute(w, struct {
+		Title string
+		Docs  []*TemplateDoc
+	}{title, docs})
+}
+
+var docPage = template.Must(template.New("doc").Funcs(template.FuncMap{"anchor": anchor}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+pre { background: #f6f6f6; padding: 8px; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<ul>
+{{- range .Docs}}
+<li><a href="#{{anchor .Name}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- range .Docs}}
+<h2 id="{{anchor .Name}}">{{.Name}}</h2>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+{{- if or .Requires .Features}}
+<p>Requires gengen {{.Requires}}{{if .Features}} with the features {{range $i, $f := .Features}}{{if $i}}, {{end}}{{$f}}{{end}}{{end}}.</p>
+{{- end}}
+{{- if .Params}}
+<table>
+<tr><th>Param</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{- range .Params}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Required}}required{{else if .DefaultText}}<code>{{.DefaultText}}</code>{{end}}</td><td>{{.Doc}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<p>Example config{{if .ExampleName}}, {{.ExampleName}}{{end}}:</p>
+<pre>{{.Example}}</pre>
+<p>Sample output:</p>
+<pre>{{.Sample}}</pre>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,69 @@
+package gengen
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocumentTemplate(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.tmpl", `{{- /*gengen {"params": {"name": {"type": "string", "required": true, "doc": "The name | title."}, "count": {"type": "int", "default": 2}}} */ -}}
+{{- /*
+This template says hello
+to someone.
+
+More details.
+*/ -}}
+hello {{.name}} {{.count}}
+`)
+	write("b.tmpl", "{{/* Lists things. */}}{{range .things}}{{.}}\n{{end}}")
+	write("b.json", `{"things": ["x", "y", "z"]}`)
+	write("gen.go", "package x\n\n//go:generate gengen -c b.json -o b.txt b.tmpl\n")
+
+	a, err := DocumentTemplate("a", filepath.Join(dir, "a.tmpl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Description != "This template says hello to someone." {
+		t.Errorf("Unexpected description %q", a.Description)
+	}
+	if len(a.Params) != 2 || a.Params[0].Name != "name" || a.Params[1].DefaultText() != "2" {
+		t.Errorf("Unexpected params %v", a.Params)
+	}
+	if a.ExampleName != "" || a.Sample != "hello name 2" {
+		t.Errorf("Unexpected example %q with sample %q", a.Example, a.Sample)
+	}
+
+	b, err := DocumentTemplate("b", filepath.Join(dir, "b.tmpl"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Description != "Lists things." || b.ExampleName != "b.json" || b.Sample != "x\ny\n...\n" || b.SampleLang != "txt" {
+		t.Errorf("Unexpected doc %+v", b)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteMarkdown(&buf, "T", []*TemplateDoc{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"- [a](#a)", "| `name` | string | required | The name \\| title. |", "| `count` | int | `2` |", "Example config, b.json:"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("Expected the Markdown to have %q:\n%s", s, buf.String())
+		}
+	}
+	buf.Reset()
+	if err = WriteHTML(&buf, "T", []*TemplateDoc{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<h2 id="a">a</h2>`) {
+		t.Errorf("Unexpected HTML:\n%s", buf.String())
+	}
+}
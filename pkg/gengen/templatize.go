@@ -0,0 +1,193 @@
+package gengen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Replacement turns a word in the identifiers of a go file into a value of the configuration of a template.
+type Replacement struct {
+	// Word is the word to replace, like "User".
+	Word string
+	// Key is the key of the configuration value that takes its place, like "ValType".
+	Key string
+}
+
+// An edit replaces the bytes of a file from off to end with a configuration value.
+type edit struct {
+	off, end int
+	key      string
+}
+
+// Templatize makes a draft template from the go source in src, along with the configuration that makes the
+// template reproduce src exactly. name is the name of the file, which is used in messages.
+//
+// The package name becomes the "package" value, and each Replacement turns its word into its value, wherever the
+// word appears in an identifier. Only identifiers change, not comments or strings, and only whole words of a
+// camel case or snake case identifier match, so the word "User" matches UserMap, NewUser and user_User, but not
+// Users. A word that starts with a lower case letter only matches at the start of an identifier or after an
+// underscore, so "user" matches user and userCount, but not newuser.
+//
+// The template declares its values as params in its front matter.
+func Templatize(name string, src []byte, replacements []Replacement) (text string, config map[string]string, err error) {
+	config = map[string]string{}
+	words := map[string]bool{}
+	for _, r := range replacements {
+		if !token.IsIdentifier(r.Word) || !token.IsIdentifier(r.Key) {
+			return "", nil, fmt.Errorf("%s=%s: the word and the key must both be go identifiers", r.Word, r.Key)
+		}
+		if words[r.Word] {
+			return "", nil, fmt.Errorf("%s is replaced more than once", r.Word)
+		}
+		if r.Key == "package" {
+			return "", nil, errors.New("package is the key of the package name, so a word cannot be replaced with it")
+		}
+		if w, ok := config[r.Key]; ok {
+			return "", nil, fmt.Errorf("%s replaces both %s and %s", r.Key, w, r.Word)
+		}
+		words[r.Word] = true
+		config[r.Key] = r.Word
+	}
+	// try the longest words first, so that a word that contains another one wins
+	sorted := append([]Replacement{}, replacements...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Word) > len(sorted[j].Word) })
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	file := fset.File(f.Package)
+	edits := []edit{{file.Offset(f.Name.Pos()), file.Offset(f.Name.End()), "package"}}
+	config["package"] = f.Name.Name
+	used := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || id == f.Name {
+			return true
+		}
+		off := file.Offset(id.Pos())
+		for i := 0; i < len(id.Name); {
+			matched := false
+			for _, r := range sorted {
+				if wordAt(id.Name, i, r.Word) {
+					edits = append(edits, edit{off + i, off + i + len(r.Word), r.Key})
+					used[r.Key] = true
+					i += len(r.Word)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				_, size := utf8.DecodeRuneInString(id.Name[i:])
+				i += size
+			}
+		}
+		return true
+	})
+	for _, r := range replacements {
+		if !used[r.Key] {
+			return "", nil, fmt.Errorf("%s: %s is not a word in any identifier", name, r.Word)
+		}
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].off < edits[j].off })
+
+	var b strings.Builder
+	writeFrontMatter(&b, name, config, len(src) > 0 && unicode.IsSpace(rune(src[0])))
+	last := 0
+	for _, e := range edits {
+		writeEscaped(&b, string(src[last:e.off]))
+		b.WriteString("{{." + e.key + "}}")
+		last = e.end
+	}
+	writeEscaped(&b, string(src[last:]))
+	text = b.String()
+
+	// make sure that the template gives back the file
+	t, err := ParseTemplate(name, text)
+	if err != nil {
+		return "", nil, err
+	}
+	dot := map[string]interface{}{}
+	for k, v := range config {
+		dot[k] = v
+	}
+	var out bytes.Buffer
+	if err = t.ApplyParams(dot); err == nil {
+		err = t.Execute(&out, dot)
+	}
+	if err == nil && !bytes.Equal(out.Bytes(), src) {
+		err = errors.New("the template does not reproduce the file")
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return text, config, nil
+}
+
+// wordAt returns true if word is a whole word of ident at position i.
+func wordAt(ident string, i int, word string) bool {
+	if !strings.HasPrefix(ident[i:], word) {
+		return false
+	}
+	if i > 0 && ident[i-1] != '_' {
+		first, _ := utf8.DecodeRuneInString(word)
+		if !unicode.IsUpper(first) {
+			return false
+		}
+	}
+	if end := i + len(word); end < len(ident) {
+		next, _ := utf8.DecodeRuneInString(ident[end:])
+		return next == '_' || unicode.IsUpper(next) || unicode.IsDigit(next)
+	}
+	return true
+}
+
+// writeFrontMatter writes the front matter of a template made by Templatize, which declares its values as
+// required params. If the file starts with white space, the front matter does not trim it.
+func writeFrontMatter(b *strings.Builder, name string, config map[string]string, space bool) {
+	params := map[string]Param{}
+	for key, word := range config {
+		doc := fmt.Sprintf("Takes the place of %s in the identifiers of %s.", word, filepath.Base(name))
+		if key == "package" {
+			doc = "The name of the package."
+		}
+		params[key] = Param{Type: "string", Required: true, Doc: doc}
+	}
+	data, _ := json.MarshalIndent(FrontMatter{Params: params}, "", "  ")
+	b.WriteString("{{/*gengen\n" + string(data) + "\n*/")
+	if space {
+		b.WriteString("}}")
+	} else {
+		b.WriteString(" -}}\n")
+	}
+}
+
+// writeEscaped writes text so that a template outputs it as it is.
+func writeEscaped(b *strings.Builder, text string) {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			b.WriteString(`{{"{{"}}`)
+			i++
+		case strings.HasPrefix(text[i:], "}}"):
+			b.WriteString(`{{"}}"}}`)
+			i++
+		case text[i] == '{' && (i == len(text)-1 || text[i+1] == '}'):
+			// it would run into the action that follows, or the one that writes the "}}"
+			b.WriteString(`{{"{"}}`)
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+}
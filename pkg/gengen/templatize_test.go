@@ -0,0 +1,57 @@
+package gengen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTemplatize(t *testing.T) {
+	src := "package users\n\n" +
+		"// UserMap holds users. {{.NotAnAction}} {{{ }}}\n" +
+		"type UserMap map[string]*User\n\n" +
+		"func NewUserMap() UserMap { return UserMap{} }\n\n" +
+		"func (m UserMap) Add(user *User, users []User) {\n" +
+		"\tvar userCount, newuser = len(users), \"User{{\"\n" +
+		"\t_, _ = userCount, newuser\n" +
+		"\tm[\"}\"] = user\n" +
+		"}\n"
+	text, config, err := Templatize("users.go", []byte(src), []Replacement{{"User", "ValType"}, {"user", "valtype"}, {"UserMap", "TypeName"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config["package"] != "users" || config["ValType"] != "User" || config["TypeName"] != "UserMap" {
+		t.Errorf("Unexpected config %v", config)
+	}
+	for _, s := range []string{"type {{.TypeName}} map[string]*{{.ValType}}", "func New{{.TypeName}}()", "var {{.valtype}}Count, newuser", "// UserMap holds users.", `"User{{"{{"}}"`} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected the template to contain %q:\n%s", s, text)
+		}
+	}
+
+	tmpl, err := ParseTemplate("users.tmpl", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := map[string]interface{}{"package": "items", "ValType": "Item", "valtype": "item", "TypeName": "Items"}
+	var out bytes.Buffer
+	if err = tmpl.Execute(&out, dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "type Items map[string]*Item\n") || !strings.Contains(out.String(), "func (m Items) Add(item *Item, users []Item)") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	if err = tmpl.ApplyParams(map[string]interface{}{}); err == nil {
+		t.Error("Expected the params of the template to be required")
+	}
+
+	if _, _, err = Templatize("users.go", []byte(src), []Replacement{{"Account", "ValType"}}); err == nil {
+		t.Error("Expected an error for a word that is not in the file")
+	}
+	if _, _, err = Templatize("users.go", []byte(src), []Replacement{{"User", "Val-Type"}}); err == nil {
+		t.Error("Expected an error for a key that is not an identifier")
+	}
+	if _, _, err = Templatize("users.go", []byte("package users\nfunc {"), nil); err == nil {
+		t.Error("Expected an error for a file that does not parse")
+	}
+}
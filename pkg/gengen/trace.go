@@ -0,0 +1,115 @@
+package gengen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// A Tracer is a Monitor that records each action and branch executed by a template, along with the lines
+// of output each one produced. Write the output of the template through the Tracer so that it can keep
+// track of the output lines.
+type Tracer struct {
+	w       io.Writer
+	written int
+	// line is the line the next byte of output will go on, and lastLine is the line of the last byte written
+	line, lastLine int
+	events         []traceEvent
+	// open holds the indexes of the events that have started but not ended, innermost last
+	open []int
+}
+
+type traceEvent struct {
+	id    int
+	value string
+	depth int
+	// start and end are the output lines produced, if written moved past start
+	start, end   int
+	startWritten int
+	written      bool
+}
+
+// NewTracer returns a Tracer that passes output on to w.
+func NewTracer(w io.Writer) *Tracer {
+	return &Tracer{w: w, line: 1}
+}
+
+// Write implements the io.Writer interface.
+func (t *Tracer) Write(p []byte) (n int, err error) {
+	n, err = t.w.Write(p)
+	if n > 0 {
+		t.lastLine = t.line + bytes.Count(p[:n-1], []byte{'\n'})
+		t.line += bytes.Count(p[:n], []byte{'\n'})
+		t.written += n
+	}
+	return
+}
+
+// Probe implements the Monitor interface.
+func (t *Tracer) Probe(id int, val interface{}) {
+	t.events = append(t.events, traceEvent{
+		id:           id,
+		value:        excerpt(fmt.Sprintf("%#v", val)),
+		depth:        len(t.open),
+		start:        t.line,
+		startWritten: t.written,
+	})
+	t.open = append(t.open, len(t.events)-1)
+}
+
+// End implements the Monitor interface.
+func (t *Tracer) End(id int) {
+	// Events left open by a break or continue end here as well.
+	for len(t.open) > 0 {
+		e := &t.events[t.open[len(t.open)-1]]
+		t.open = t.open[:len(t.open)-1]
+		t.end(e)
+		if e.id == id {
+			break
+		}
+	}
+}
+
+func (t *Tracer) end(e *traceEvent) {
+	if t.written > e.startWritten {
+		e.written = true
+		e.end = t.lastLine
+	}
+}
+
+// Report writes the trace to w, one line per event, nested by statement. probes are the probes returned by
+// Instrument. Events still open, for example because execution failed, end at the last output written.
+func (t *Tracer) Report(w io.Writer, probes []Probe) {
+	for _, i := range t.open {
+		t.end(&t.events[i])
+	}
+	t.open = nil
+
+	for _, e := range t.events {
+		p := probes[e.id]
+		var what string
+		switch p.Kind {
+		case "action":
+			what = p.Source + " = " + e.value
+		case "template":
+			what = p.Source
+		case "range":
+			what = p.Source + " iteration"
+		case "range-else":
+			what = p.Source + " is empty, else branch"
+		default:
+			if strings.HasSuffix(p.Kind, "-else") {
+				what = p.Source + " is " + e.value + ", else branch"
+			} else {
+				what = p.Source + " is " + e.value + ", then branch"
+			}
+		}
+		out := "no output"
+		if e.written {
+			out = fmt.Sprintf("output lines %d-%d", e.start, e.end)
+		}
+		fmt.Fprintf(w, "%s%s:%d:%d: %s => %s\n", strings.Repeat("  ", e.depth), filepath.Base(p.File), p.Line, p.Col, what, out)
+	}
+}
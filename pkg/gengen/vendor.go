@@ -0,0 +1,413 @@
+package gengen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goradd/gofile/pkg/sys"
+)
+
+// LockFile is the name of the file in a vendor directory that holds the hashes of the files in it.
+const LockFile = "gengen.lock"
+
+const lockHeader = "# The sha256 hashes of the files vendored by gengen, which gengen checks before it uses them.\n" +
+	"# Run gengen vendor again to accept changes to them.\n"
+
+// extendsRE matches the name of the template in an extends action.
+var extendsRE = regexp.MustCompile(`\bextends\s+("(?:[^"\\\n]|\\.)*")`)
+
+// A Vendor copies the templates, configuration files and data files that a project uses from outside of it,
+// like the templates of the Library, into a directory of the project, and changes the references to them
+// to refer to the copies.
+type Vendor struct {
+	// Project is the directory of the project. Files in it are used where they are.
+	Project string
+	// Dir is the directory the files are copied to. Files from a module are put in the directory of their
+	// module path in it, like github.com/goradd/gengen/templates/map_src/slice_map.tmpl.
+	Dir string
+	// copies maps the files that were copied to their copies
+	copies map[string]string
+}
+
+// inProject returns true if path is in the project.
+func (v *Vendor) inProject(path string) bool {
+	rel, err := filepath.Rel(v.Project, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// copyPath returns the path of the copy of a file that is outside of the project.
+func (v *Vendor) copyPath(path string) string {
+	if modules == nil {
+		modules, _ = sys.ModulePaths()
+	}
+	var best, bestDir string
+	for modPath, dir := range modules {
+		rel, err := filepath.Rel(dir, path)
+		if err == nil && !strings.HasPrefix(rel, "..") && len(dir) > len(bestDir) {
+			best, bestDir = filepath.Join(filepath.FromSlash(modPath), rel), dir
+		}
+	}
+	if best == "" {
+		best = filepath.Base(path)
+	}
+	return filepath.Join(v.Dir, best)
+}
+
+// File copies a configuration or data file to the vendor directory, if it is not in the project, and returns
+// the path to use for it.
+func (v *Vendor) File(path string) (string, error) {
+	if v.inProject(path) {
+		return path, nil
+	}
+	if dest, ok := v.copies[path]; ok {
+		return dest, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	dest := v.copyPath(path)
+	if err = v.write(path, dest, data); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// Template copies a template file to the vendor directory, if it is not in the project, along with the
+// templates it extends, and returns the path to use for it. The extends action of each template is changed to
+// refer to the copy of the template it extends. A template in the project is changed in place if the
+// template it extends is copied. delims are the delimiters of the template, if its front matter does not
+// set them.
+func (v *Vendor) Template(path, delims string) (string, error) {
+	if dest, ok := v.copies[path]; ok {
+		return dest, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	orig := data
+	dest := path
+	if !v.inProject(path) {
+		dest = v.copyPath(path)
+	}
+
+	t, err := ParseTemplateOptions(path, string(data), Options{Delims: delims})
+	if err != nil {
+		return "", err
+	}
+	if len(t.Files) > 1 {
+		m := extendsRE.FindSubmatchIndex(data)
+		if m == nil {
+			return "", fmt.Errorf("%s: cannot find the extends action", path)
+		}
+		base, err := v.Template(t.Files[1], "")
+		if err != nil {
+			return "", err
+		}
+		if base != t.Files[1] || dest != path {
+			rel, err := filepath.Rel(filepath.Dir(dest), base)
+			if err != nil {
+				return "", err
+			}
+			quoted := strconv.Quote(filepath.ToSlash(rel))
+			data = append(append(append([]byte(nil), data[:m[2]]...), quoted...), data[m[3]:]...)
+		}
+	}
+
+	if dest == path {
+		if !bytes.Equal(data, orig) {
+			if err = WriteFile(path, data); err != nil {
+				return "", err
+			}
+		}
+		return path, nil
+	}
+	if err = v.write(path, dest, data); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// write writes the copy of the file at path.
+func (v *Vendor) write(path, dest string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := WriteFile(dest, data); err != nil {
+		return err
+	}
+	if v.copies == nil {
+		v.copies = make(map[string]string)
+	}
+	v.copies[path] = dest
+	return nil
+}
+
+// ref returns the reference to use for the file at path in a file in dir.
+func ref(path, dir string) (string, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Manifest vendors the files of the jobs of the manifest at path, and changes the manifest to refer to the
+// copies. It returns true if the manifest was changed.
+func (v *Vendor) Manifest(path string) (bool, error) {
+	m, err := LoadManifest(path)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	idx := bytes.IndexRune(data, '{')
+	var raw Manifest
+	if err = json.Unmarshal(data[idx:], &raw); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	changed := false
+	update := func(field *string, resolved, vendored string) error {
+		if resolved == vendored {
+			return nil
+		}
+		s, err := ref(vendored, dir)
+		*field, changed = s, true
+		return err
+	}
+	for i, job := range m.Jobs {
+		r := &raw.Jobs[i]
+		tmpl, err := v.Template(job.Template, job.Delims)
+		if err == nil {
+			err = update(&r.Template, job.Template, tmpl)
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: job %d: %w", path, i+1, err)
+		}
+		config, err := v.File(job.Config)
+		if err == nil {
+			err = update(&r.Config, job.Config, config)
+		}
+		for name, file := range job.Data {
+			if err == nil {
+				var d string
+				if d, err = v.File(file); err == nil {
+					s := r.Data[name]
+					err = update(&s, file, d)
+					r.Data[name] = s
+				}
+			}
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: job %d: %w", path, i+1, err)
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	out, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	return true, WriteFile(path, append(append(data[:idx:idx], out...), '\n'))
+}
+
+// GoFile vendors the files of the go:generate lines of the go file at path that run gengen, and changes the
+// lines to refer to the copies. The manifests that the lines run are vendored with Manifest. It returns
+// true if the file was changed.
+func (v *Vendor) GoFile(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	dir := filepath.Dir(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	changed := false
+	for n, text := range lines {
+		if !strings.HasPrefix(text, "//go:generate ") {
+			continue
+		}
+		words := splitGenerate(strings.TrimSpace(text[len("//go:generate "):]))
+		args := gengenArgs(words)
+		if args == nil {
+			continue
+		}
+		line, err := parseGenerateLine(args, path)
+		if err != nil {
+			return false, fmt.Errorf("%s:%d: %w", path, n+1, err)
+		}
+		if line.manifest != "" {
+			if _, err = v.Manifest(line.manifest); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		// the replacements of the words of the line that refer to files
+		replace := make(map[int]string)
+		job := line.job
+		start := len(words) - len(args)
+		for i := start; i < len(words); i++ {
+			word := words[i]
+			if !strings.HasPrefix(word, "-") || word == "-" {
+				if job.Template != "" {
+					if tmpl, err := v.Template(job.Template, job.Delims); err != nil {
+						return false, err
+					} else if tmpl != job.Template {
+						replace[i], _ = ref(tmpl, dir)
+					}
+				}
+				continue
+			}
+			name := strings.TrimLeft(word, "-")
+			value, at, prefix := "", i, ""
+			if j := strings.Index(name, "="); j >= 0 {
+				value, prefix = name[j+1:], word[:len(word)-len(name)+j+1]
+				name = name[:j]
+			} else if valueOptions[name] && i+1 < len(words) {
+				i++
+				value, at = words[i], i
+			}
+			switch name {
+			case "c":
+				if config, err := v.File(job.Config); err != nil {
+					return false, err
+				} else if config != job.Config {
+					s, _ := ref(config, dir)
+					replace[at] = prefix + s
+				}
+			case "data":
+				k := strings.Index(value, "=")
+				if k <= 0 {
+					continue
+				}
+				file := job.Data[value[:k]]
+				if d, err := v.File(file); err != nil {
+					return false, err
+				} else if d != file {
+					s, _ := ref(d, dir)
+					replace[at] = prefix + value[:k+1] + s
+				}
+			}
+		}
+		if len(replace) == 0 {
+			continue
+		}
+		for i, word := range words {
+			if r, ok := replace[i]; ok {
+				word = r
+			}
+			if strings.ContainsAny(word, " \t\"") {
+				word = strconv.Quote(word)
+			}
+			words[i] = word
+		}
+		end := text[len(strings.TrimRight(text, "\r\n")):]
+		lines[n] = "//go:generate " + strings.Join(words, " ") + end
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, WriteFile(path, []byte(strings.Join(lines, "")))
+}
+
+// Lock writes the lock file of the vendor directory, with the hashes of the files in it.
+func (v *Vendor) Lock() error {
+	var b strings.Builder
+	b.WriteString(lockHeader)
+	err := filepath.Walk(v.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Name() == LockFile {
+			return err
+		}
+		h, err := fileHash(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(v.Dir, path)
+		fmt.Fprintf(&b, "%s  %s\n", h, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return WriteFile(filepath.Join(v.Dir, LockFile), []byte(b.String()))
+}
+
+// CheckLocks checks that the files at paths that are in a vendor directory have not changed since they were
+// vendored, by comparing them to the hashes in the lock file of the directory.
+func CheckLocks(paths ...string) error {
+	locks := make(map[string]map[string]string)
+	var problems []string
+	for _, path := range paths {
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			lock, ok := locks[dir]
+			if !ok {
+				var err error
+				if lock, err = loadLock(filepath.Join(dir, LockFile)); err != nil {
+					return err
+				}
+				locks[dir] = lock
+			}
+			if lock != nil {
+				rel, _ := filepath.Rel(dir, path)
+				if want, ok := lock[filepath.ToSlash(rel)]; ok {
+					if got, err := fileHash(path); err != nil {
+						return err
+					} else if got != want {
+						problems = append(problems, fmt.Sprintf("%s was changed after it was vendored; run gengen vendor again to accept the change", path))
+					}
+				}
+				break
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	if problems != nil {
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// loadLock reads the hashes of a lock file, by the slash separated paths of the files relative to its
+// directory. It returns nil if there is no lock file.
+func loadLock(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hashes := make(map[string]string)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "  ")
+		if i < 0 {
+			return nil, fmt.Errorf("%s: bad line %q", path, line)
+		}
+		hashes[line[i+2:]] = line[:i]
+	}
+	return hashes, s.Err()
+}
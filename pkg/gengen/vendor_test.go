@@ -0,0 +1,81 @@
+package gengen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVendor(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, text string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	write("lib/base.tmpl", "package {{.pkg}}\n{{block \"body\" .}}{{end}}\n")
+	write("lib/a.json", `{"pkg": "a"}`)
+	write("proj/a.tmpl", "{{extends \"../lib/base.tmpl\"}}{{define \"body\"}}// body{{end}}")
+	write("proj/m.json", "/* jobs */\n"+`{"jobs": [{"template": "a.tmpl", "config": "../lib/a.json", "output": "a.go"}, {"template": "../lib/base.tmpl", "config": "../lib/a.json", "output": "b.go"}]}`)
+
+	v := &Vendor{Project: filepath.Join(dir, "proj"), Dir: filepath.Join(dir, "proj", "vendored")}
+	changed, err := v.Manifest(filepath.Join(dir, "proj", "m.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("Expected the manifest to change")
+	}
+	if m := read("proj/m.json"); !strings.HasPrefix(m, "/* jobs */\n{") || !strings.Contains(m, `"config": "vendored/a.json"`) || !strings.Contains(m, `"template": "vendored/base.tmpl"`) {
+		t.Errorf("Unexpected manifest %s", m)
+	}
+	if s := read("proj/a.tmpl"); s != "{{extends \"vendored/base.tmpl\"}}{{define \"body\"}}// body{{end}}" {
+		t.Errorf("Unexpected template %s", s)
+	}
+	if s := read("proj/vendored/base.tmpl"); s != read("lib/base.tmpl") {
+		t.Errorf("Unexpected copy %s", s)
+	}
+	m, err := LoadManifest(filepath.Join(dir, "proj", "m.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := m.Jobs[0].Run(); err != nil || string(out) != "package a\n// body\n" {
+		t.Errorf("Unexpected output %q %v", out, err)
+	}
+
+	if changed, err = v.Manifest(filepath.Join(dir, "proj", "m.json")); err != nil || changed {
+		t.Errorf("Expected vendoring again to change nothing, got %v %v", changed, err)
+	}
+
+	// options that take a value keep it
+	write("proj/gen.go", "package a\n\n//go:generate gengen -timeout 10s -c ../lib/a.json -o c.go ../lib/base.tmpl\n")
+	if _, err = v.GoFile(filepath.Join(dir, "proj", "gen.go")); err != nil {
+		t.Fatal(err)
+	}
+	if s := read("proj/gen.go"); s != "package a\n\n//go:generate gengen -timeout 10s -c vendored/a.json -o c.go vendored/base.tmpl\n" {
+		t.Errorf("Unexpected go file %s", s)
+	}
+	if err = v.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	copies := []string{filepath.Join(v.Dir, "base.tmpl"), filepath.Join(v.Dir, "a.json"), filepath.Join(dir, "proj", "a.tmpl")}
+	if err = CheckLocks(copies...); err != nil {
+		t.Error(err)
+	}
+	write("proj/vendored/a.json", `{"pkg": "b"}`)
+	if err = CheckLocks(copies...); err == nil || !strings.Contains(err.Error(), "a.json was changed") {
+		t.Errorf("Expected the change to be found, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package gengen
+
+// Version is the version of gengen. It is part of the key of cached outputs, so that a new version
+// regenerates them. Templates can require a version with the requires setting of their front matter.
+const Version = "0.3.0"
+
+// Features describes the features of gengen that templates can require with the features setting of their
+// front matter. Once a feature is listed here, it keeps its name.
+var Features = map[string]string{
+	"data":        "Data files bound to .Data with the -data option or the data of a manifest job.",
+	"delims":      "The delims setting of front matter.",
+	"extends":     "Templates that extend other templates with the extends action.",
+	"funcs":       "The lcFirst and ucFirst functions.",
+	"generic":     "The generic setting of the map templates, which makes aliases of the maps of pkg/maps/generic.",
+	"lib":         "References to the templates of the Library, like lib:maps/slice_map.",
+	"limits":      "The -timeout, -max-output and -max-depth options.",
+	"params":      "The params setting of front matter, and its types.",
+	"postprocess": "The processors of the output listed with the Postprocess key of a config, or the postprocess of a manifest job.",
+	"regions":     "Protected regions that keep their content when the output is generated again.",
+	"requires":    "The requires and features settings of front matter.",
+	"restricted":  "The -restricted option, which limits the templates a template can extend.",
+	"shared":      "The shared function, and the shared file of a package.",
+}
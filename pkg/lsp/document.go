@@ -0,0 +1,340 @@
+package lsp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/goradd/gengen/pkg/gengen"
+)
+
+// A position is a place in a document, as a zero based line, and a character offset in UTF-16 code units.
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type span struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string `json:"uri"`
+	Range span   `json:"range"`
+}
+
+type diagnostic struct {
+	Range    span   `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// Severities of diagnostics.
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+type markup struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markup `json:"contents"`
+	Range    span   `json:"range"`
+}
+
+type completionItem struct {
+	Label         string  `json:"label"`
+	Kind          int     `json:"kind"`
+	Detail        string  `json:"detail,omitempty"`
+	Documentation *markup `json:"documentation,omitempty"`
+}
+
+// completionField is the kind of completion item for a field.
+const completionField = 5
+
+// A document is a template that is open in the editor.
+type document struct {
+	path  string
+	lines []string
+	// tmpl is the template parsed from the last version of the text that parsed without errors, so that
+	// hover and completion keep working while an edit is under way.
+	tmpl        *gengen.Template
+	diagnostics []diagnostic
+}
+
+// errorPosRE matches the line, and maybe the column, that follow the file name at the start of an error.
+var errorPosRE = regexp.MustCompile(`^:(\d+):(?:(\d+):)? ((?s).*)$`)
+
+// update replaces the text of the document and checks it.
+func (d *document) update(text string) {
+	d.lines = strings.Split(text, "\n")
+	d.diagnostics = nil
+
+	tmpl, err := gengen.ParseTemplate(d.path, text)
+	if err != nil {
+		d.diagnostics = append(d.diagnostics, d.errorDiagnostic(err))
+		return
+	}
+	d.tmpl = tmpl
+	for _, issue := range tmpl.Diagnose() {
+		if issue.File != d.path {
+			continue // it is in a template this one extends
+		}
+		start := position{issue.Line - 1, 0}
+		if start.Line >= 0 && start.Line < len(d.lines) {
+			line := d.lines[start.Line]
+			end := issue.Col
+			for end < len(line) && (isWordByte(line[end]) || strings.IndexByte(`.$"`, line[end]) >= 0) {
+				end++
+			}
+			start.Character = utf16Len(line[:issue.Col])
+			d.diagnostics = append(d.diagnostics, diagnostic{
+				Range:    span{start, position{start.Line, utf16Len(line[:end])}},
+				Severity: severityWarning,
+				Source:   "gengen",
+				Message:  issue.Msg,
+			})
+		}
+	}
+}
+
+// errorDiagnostic returns the diagnostic of an error that stopped the template from parsing. If the error is
+// in the document, like the errors of the template parser, it is put on the line it names.
+func (d *document) errorDiagnostic(err error) diagnostic {
+	diag := diagnostic{Severity: severityError, Source: "gengen", Message: err.Error()}
+	msg := strings.TrimPrefix(err.Error(), "template: ")
+	if !strings.HasPrefix(msg, d.path) {
+		return diag
+	}
+	if m := errorPosRE.FindStringSubmatch(msg[len(d.path):]); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		diag.Range = d.lineSpan(line - 1)
+		if col, err := strconv.Atoi(m[2]); err == nil && line-1 < len(d.lines) && col <= len(d.lines[line-1]) {
+			diag.Range.Start.Character = utf16Len(d.lines[line-1][:col])
+		}
+		diag.Message = m[3]
+	}
+	return diag
+}
+
+// lineSpan returns the span of the text of a line, without its indentation.
+func (d *document) lineSpan(n int) span {
+	if n < 0 || n >= len(d.lines) {
+		return span{}
+	}
+	line := d.lines[n]
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	return span{position{n, indent}, position{n, utf16Len(line)}}
+}
+
+// delims returns the delimiters of the template.
+func (d *document) delims() (left, right string) {
+	if d.tmpl != nil {
+		if f := strings.Fields(d.tmpl.FrontMatter.Delims); len(f) == 2 {
+			return f[0], f[1]
+		}
+	}
+	return "{{", "}}"
+}
+
+// at returns the text of the line of pos, and the byte offset of pos in it. ok is false if pos is not in
+// the document.
+func (d *document) at(pos position) (line string, col int, ok bool) {
+	if pos.Line < 0 || pos.Line >= len(d.lines) {
+		return "", 0, false
+	}
+	line = d.lines[pos.Line]
+	units := 0
+	for i, r := range line {
+		if units >= pos.Character {
+			return line, i, true
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return line, len(line), true
+}
+
+// inAction returns true if the text before col is inside an action of the template.
+func (d *document) inAction(line string, col int) bool {
+	left, right := d.delims()
+	return strings.LastIndex(line[:col], left) > strings.LastIndex(line[:col], right)
+}
+
+// key returns the configuration key that the word at col of line refers to, like "keytype" in
+// "{{.keytype}}", along with its start and end.
+func (d *document) key(line string, col int) (key string, start, end int) {
+	start, end = col, col
+	for start > 0 && isWordByte(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWordByte(line[end]) {
+		end++
+	}
+	if start == end || !d.isKey(line, start) {
+		return "", 0, 0
+	}
+	return line[start:end], start, end
+}
+
+// isKey returns true if a word that starts at start of line is a configuration key, which is a field of dot or
+// of $ in an action.
+func (d *document) isKey(line string, start int) bool {
+	if start == 0 || line[start-1] != '.' || !d.inAction(line, start) {
+		return false
+	}
+	// a field of a field is not a key
+	return start == 1 || !isWordByte(line[start-2]) && line[start-2] != ')'
+}
+
+// hover returns the documentation of the param under pos, or nil.
+func (d *document) hover(pos position) *hover {
+	line, col, ok := d.at(pos)
+	if !ok || d.tmpl == nil {
+		return nil
+	}
+	key, start, end := d.key(line, col)
+	p, ok := d.tmpl.FrontMatter.Params[key]
+	if key == "" || !ok {
+		return nil
+	}
+	return &hover{
+		Contents: markup{"markdown", paramDoc(key, p)},
+		Range:    span{position{pos.Line, utf16Len(line[:start])}, position{pos.Line, utf16Len(line[:end])}},
+	}
+}
+
+// paramDoc describes a param in markdown.
+func paramDoc(key string, p gengen.Param) string {
+	s := "**" + key + "**"
+	if p.Type != "" {
+		s += " `" + p.Type + "`"
+	}
+	if p.Doc != "" {
+		s += "\n\n" + p.Doc
+	}
+	if p.Required {
+		s += "\n\nRequired."
+	} else if p.Default != nil {
+		s += fmt.Sprintf("\n\nDefaults to `%#v`.", p.Default)
+	}
+	return s
+}
+
+// complete returns the configuration keys that can be typed at pos.
+func (d *document) complete(pos position) []completionItem {
+	line, col, ok := d.at(pos)
+	if !ok || d.tmpl == nil {
+		return nil
+	}
+	start := col
+	for start > 0 && isWordByte(line[start-1]) {
+		start--
+	}
+	if !d.isKey(line, start) {
+		return nil
+	}
+
+	items := []completionItem{}
+	if params := d.tmpl.FrontMatter.Params; len(params) > 0 {
+		for key, p := range params {
+			items = append(items, completionItem{Label: key, Kind: completionField, Detail: p.Type, Documentation: &markup{"markdown", paramDoc(key, p)}})
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	} else {
+		// without params, offer the keys the template already uses
+		for _, key := range d.tmpl.Keys() {
+			items = append(items, completionItem{Label: key, Kind: completionField})
+		}
+	}
+	return items
+}
+
+// definition returns where the template named by the string under pos is defined, if the string is the
+// name in a template action or a call of the shared function.
+func (d *document) definition(pos position) *location {
+	line, col, ok := d.at(pos)
+	if !ok {
+		return nil
+	}
+	start, end := quoted(line, col)
+	if start < 0 || !d.inAction(line, start) {
+		return nil
+	}
+	name, err := strconv.Unquote(line[start:end])
+	if err != nil {
+		return nil
+	}
+	before := strings.TrimSpace(line[:start])
+	if !strings.HasSuffix(before, "template") && !strings.HasSuffix(before, "shared") {
+		return nil
+	}
+
+	files := []string{d.path}
+	if d.tmpl != nil {
+		files = d.tmpl.Files
+	}
+	re := regexp.MustCompile(`\b(define|block)\s+("` + regexp.QuoteMeta(name) + `")`)
+	for _, file := range files {
+		lines := d.lines
+		if file != d.path {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			lines = strings.Split(string(data), "\n")
+		}
+		for n, text := range lines {
+			if m := re.FindStringSubmatchIndex(text); m != nil {
+				return &location{pathURI(file), span{position{n, utf16Len(text[:m[4]])}, position{n, utf16Len(text[:m[5]])}}}
+			}
+		}
+	}
+	return nil
+}
+
+// quoted returns the start and end of the quoted string in line that col is in, or -1 if there is none.
+func quoted(line string, col int) (start, end int) {
+	for i := 0; i < len(line); i++ {
+		if line[i] != '"' {
+			continue
+		}
+		j := i + 1
+		for j < len(line) && line[j] != '"' {
+			if line[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(line) {
+			break
+		}
+		if col >= i && col <= j {
+			return i, j + 1
+		}
+		i = j
+	}
+	return -1, -1
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= utf8.RuneSelf
+}
+
+// utf16Len returns the length of s in UTF-16 code units, which is how positions count characters.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return n
+}
@@ -0,0 +1,206 @@
+// Package lsp is a language server for gengen templates, which gives editors diagnostics, hover documentation
+// of params, completion of configuration keys and go-to-definition of the templates that are called.
+//
+// It speaks the Language Server Protocol over a pair of streams, normally stdin and stdout, as in "gengen lsp".
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goradd/gengen/pkg/gengen"
+)
+
+// A server holds the templates that are open in the editor.
+type server struct {
+	w    io.Writer
+	docs map[string]*document
+}
+
+// message is a json-rpc request, notification or response.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// Error codes of json-rpc.
+const (
+	parseError     = -32700
+	methodNotFound = -32601
+	invalidParams  = -32602
+)
+
+// Serve runs the language server. It reads messages from r and writes messages to w, until the client asks
+// it to exit or r is closed.
+func Serve(r io.Reader, w io.Writer) error {
+	s := &server{w: w, docs: make(map[string]*document)}
+	reader := textproto.NewReader(bufio.NewReader(r))
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			return fmt.Errorf("bad Content-Length header: %w", err)
+		}
+		body := make([]byte, length)
+		if _, err = io.ReadFull(reader.R, body); err != nil {
+			return err
+		}
+
+		var m message
+		if err = json.Unmarshal(body, &m); err != nil {
+			if err = s.reply(json.RawMessage("null"), nil, &rpcError{parseError, err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.Method == "exit" {
+			return nil
+		}
+		result, rerr := s.handle(m)
+		if m.ID == nil {
+			continue // a notification, which has no response
+		}
+		if err = s.reply(m.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// handle handles a message, and returns the result or error to respond with if it is a request.
+func (s *server) handle(m message) (interface{}, *rpcError) {
+	var p struct {
+		TextDocument struct {
+			URI  string `json:"uri"`
+			Text string `json:"text"`
+		} `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+		Position position `json:"position"`
+	}
+	if len(m.Params) > 0 {
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil, &rpcError{invalidParams, err.Error()}
+		}
+	}
+	uri := p.TextDocument.URI
+	doc := s.docs[uri]
+
+	switch m.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // the full text is sent on each change
+				"hoverProvider":      true,
+				"completionProvider": map[string]interface{}{"triggerCharacters": []string{"."}},
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "gengen", "version": gengen.Version},
+		}, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		doc = &document{path: uriPath(uri)}
+		s.docs[uri] = doc
+		doc.update(p.TextDocument.Text)
+		return nil, s.publish(uri, doc.diagnostics)
+	case "textDocument/didChange":
+		if doc == nil || len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		doc.update(p.ContentChanges[len(p.ContentChanges)-1].Text)
+		return nil, s.publish(uri, doc.diagnostics)
+	case "textDocument/didClose":
+		delete(s.docs, uri)
+		return nil, s.publish(uri, nil)
+	case "textDocument/hover":
+		if doc == nil {
+			return nil, nil
+		}
+		return doc.hover(p.Position), nil
+	case "textDocument/completion":
+		if doc == nil {
+			return nil, nil
+		}
+		return doc.complete(p.Position), nil
+	case "textDocument/definition":
+		if doc == nil {
+			return nil, nil
+		}
+		return doc.definition(p.Position), nil
+	}
+	if m.ID != nil {
+		return nil, &rpcError{methodNotFound, "method not supported: " + m.Method}
+	}
+	return nil, nil
+}
+
+// publish sends the diagnostics of a document to the client.
+func (s *server) publish(uri string, diagnostics []diagnostic) *rpcError {
+	if diagnostics == nil {
+		diagnostics = []diagnostic{}
+	}
+	err := s.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "textDocument/publishDiagnostics",
+		"params":  map[string]interface{}{"uri": uri, "diagnostics": diagnostics},
+	})
+	if err != nil {
+		return &rpcError{Code: -32603, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *server) reply(id json.RawMessage, result interface{}, rerr *rpcError) error {
+	m := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if rerr != nil {
+		m["error"] = rerr
+	} else {
+		m["result"] = result
+	}
+	return s.send(m)
+}
+
+func (s *server) send(m interface{}) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// uriPath returns the file path of a file uri, or the uri itself if it is not one.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathURI returns the file uri of a path.
+func pathURI(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
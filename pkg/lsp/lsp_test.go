@@ -0,0 +1,191 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// A client talks to a server running in the background.
+type client struct {
+	t    *testing.T
+	w    io.Writer
+	r    *textproto.Reader
+	id   int
+	done chan error
+}
+
+func newClient(t *testing.T) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, w: inW, r: textproto.NewReader(bufio.NewReader(outR)), done: make(chan error, 1)}
+	go func() {
+		err := Serve(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *client) send(m map[string]interface{}) {
+	m["jsonrpc"] = "2.0"
+	data, _ := json.Marshal(m)
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(data), data); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// read returns the next message from the server.
+func (c *client) read() map[string]interface{} {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	n, _ := strconv.Atoi(header.Get("Content-Length"))
+	body := make([]byte, n)
+	if _, err = io.ReadFull(c.r.R, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(body, &m); err != nil {
+		c.t.Fatal(err)
+	}
+	return m
+}
+
+// call sends a request and returns the response.
+func (c *client) call(method string, params interface{}) map[string]interface{} {
+	c.id++
+	c.send(map[string]interface{}{"id": c.id, "method": method, "params": params})
+	m := c.read()
+	if m["id"] != float64(c.id) {
+		c.t.Fatalf("Expected the response to %s, got %v", method, m)
+	}
+	return m
+}
+
+// notify sends a notification and returns the diagnostics the server publishes for it.
+func (c *client) notify(method string, params interface{}) []interface{} {
+	c.send(map[string]interface{}{"method": method, "params": params})
+	m := c.read()
+	if m["method"] != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("Expected diagnostics, got %v", m)
+	}
+	return m["params"].(map[string]interface{})["diagnostics"].([]interface{})
+}
+
+func at(uri string, line, char int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     map[string]interface{}{"line": line, "character": char},
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.tmpl")
+	if err := os.WriteFile(base, []byte("{{define \"header\"}}// header{{end}}\n{{block \"body\" .}}{{end}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "a.tmpl")
+	uri := pathURI(path)
+	text := `{{/*gengen {"params": {"keytype": {"type": "string", "doc": "The type of the keys."}, "sort": {"type": "bool", "default": true}}} */}}
+package a
+{{template "header" .}}
+{{template "Name" .}}
+{{define "Name"}}type {{.keytype}}Map{{end}}
+{{if .sort}}{{.other}}{{end}}
+`
+	c := newClient(t)
+	resp := c.call("initialize", map[string]interface{}{})
+	caps := resp["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	if caps["hoverProvider"] != true || caps["definitionProvider"] != true {
+		t.Errorf("Unexpected capabilities %v", caps)
+	}
+
+	diags := c.notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri, "text": text}})
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", diags)
+	}
+	msgs := fmt.Sprint(diags)
+	if !strings.Contains(msgs, `template "header" is not defined`) {
+		t.Errorf("Expected the header template to be undefined, got %v", diags)
+	}
+	if !strings.Contains(msgs, "other") {
+		t.Errorf("Expected the other key to be undeclared, got %v", diags)
+	}
+	r := diags[1].(map[string]interface{})["range"].(map[string]interface{})
+	if fmt.Sprint(r["start"]) != "map[character:14 line:5]" || fmt.Sprint(r["end"]) != "map[character:20 line:5]" {
+		t.Errorf("Unexpected range %v", r)
+	}
+
+	// a parse error keeps the last template for hover and completion
+	diags = c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri},
+		"contentChanges": []interface{}{map[string]interface{}{"text": strings.Replace(text, "{{end}}\n", "\n", 1)}},
+	})
+	if len(diags) != 1 || !strings.Contains(fmt.Sprint(diags), "unexpected EOF") {
+		t.Errorf("Expected a parse error, got %v", diags)
+	}
+	diags = c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri},
+		"contentChanges": []interface{}{map[string]interface{}{"text": text}},
+	})
+	if len(diags) != 2 {
+		t.Errorf("Expected the diagnostics of the fixed text, got %v", diags)
+	}
+
+	resp = c.call("textDocument/hover", at(uri, 4, 25))
+	if v := fmt.Sprint(resp["result"]); !strings.Contains(v, "**keytype** `string`") || !strings.Contains(v, "The type of the keys.") {
+		t.Errorf("Unexpected hover %v", resp)
+	}
+	if resp = c.call("textDocument/hover", at(uri, 1, 3)); resp["result"] != nil {
+		t.Errorf("Expected no hover outside of an action, got %v", resp)
+	}
+
+	resp = c.call("textDocument/completion", at(uri, 5, 6))
+	items := resp["result"].([]interface{})
+	if len(items) != 2 || items[0].(map[string]interface{})["label"] != "keytype" {
+		t.Errorf("Unexpected completion %v", items)
+	}
+
+	resp = c.call("textDocument/definition", at(uri, 3, 13))
+	loc := resp["result"].(map[string]interface{})
+	if loc["uri"] != uri || fmt.Sprint(loc["range"].(map[string]interface{})["start"]) != "map[character:9 line:4]" {
+		t.Errorf("Unexpected definition %v", loc)
+	}
+
+	// a template that extends another finds definitions in it
+	uri2 := pathURI(filepath.Join(dir, "b.tmpl"))
+	diags = c.notify("textDocument/didOpen", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri2, "text": "{{extends \"base.tmpl\"}}\npackage b"}})
+	if len(diags) != 1 || fmt.Sprint(diags[0].(map[string]interface{})["range"]) != "map[end:map[character:23 line:0] start:map[character:23 line:0]]" {
+		t.Errorf("Expected an error where the text starts, got %v", diags)
+	}
+	diags = c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri2},
+		"contentChanges": []interface{}{map[string]interface{}{"text": "{{extends \"base.tmpl\"}}\n{{define \"body\"}}{{template \"header\" .}}{{end}}"}},
+	})
+	if len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+	resp = c.call("textDocument/definition", at(uri2, 1, 30))
+	if loc, _ = resp["result"].(map[string]interface{}); loc == nil || loc["uri"] != pathURI(base) {
+		t.Errorf("Expected the definition in the base template, got %v", resp)
+	}
+
+	if resp = c.call("textDocument/formatting", at(uri, 0, 0)); resp["error"] == nil {
+		t.Errorf("Expected an error for an unsupported method, got %v", resp)
+	}
+	c.call("shutdown", nil)
+	c.send(map[string]interface{}{"method": "exit"})
+	if err := <-c.done; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,70 @@
+package generic
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// equal returns true if a and b are equal. They are compared with == as interface values, so like == on
+// interface values, it panics if their type cannot be compared.
+func equal[T any](a, b T) bool {
+	return any(a) == any(b)
+}
+
+// less returns true if a is less than b. It panics if values of their type cannot be compared with <.
+func less[T any](a, b T) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsValid() && vb.IsValid() && va.Kind() == vb.Kind() {
+		switch va.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return va.Int() < vb.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return va.Uint() < vb.Uint()
+		case reflect.Float32, reflect.Float64:
+			return va.Float() < vb.Float()
+		case reflect.String:
+			return va.String() < vb.String()
+		}
+	}
+	panic(fmt.Sprintf("values of type %T cannot be compared with <", a))
+}
+
+// ordered returns true if values of type T can be compared with less.
+func ordered[T any]() bool {
+	switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// sortKeys sorts keys, by their values if they can be compared with <, or else by how they are printed, so that
+// the maps print in the same order each time.
+func sortKeys[K comparable](keys []K) {
+	if ordered[K]() {
+		sort.Slice(keys, func(a, b int) bool { return less(keys[a], keys[b]) })
+	} else {
+		sort.Slice(keys, func(a, b int) bool { return fmt.Sprintf("%#v", keys[a]) < fmt.Sprintf("%#v", keys[b]) })
+	}
+}
+
+// copyOf returns a copy of v made by its Copy method, if it has one that returns a T, or else v itself.
+func copyOf[T any](v T) T {
+	if c, ok := any(v).(interface{ Copy() T }); ok {
+		return c.Copy()
+	}
+	return v
+}
+
+// loadAs returns the value of key in m as a T, and whether it exists and is a T. It is for maps with
+// interface{} values.
+func loadAs[T any, K comparable, V any](m Loader[K, V], key K) (val T, ok bool) {
+	var v V
+	if v, ok = m.Load(key); ok {
+		val, ok = any(v).(T)
+	}
+	return
+}
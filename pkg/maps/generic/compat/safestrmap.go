@@ -0,0 +1,33 @@
+// Code generated by gengen. DO NOT EDIT.
+
+package compat
+
+import (
+	"encoding/gob"
+	"github.com/goradd/gengen/pkg/maps/generic"
+)
+
+// SafeStringMap is a generic.SafeMap that maps string's to string's.
+type SafeStringMap = generic.SafeMap[string, string]
+
+// NewSafeStringMap creates a new map that maps string's to string's.
+func NewSafeStringMap() *SafeStringMap {
+	return generic.NewSafeMap[string, string]()
+}
+
+// NewSafeStringMapFrom creates a new SafeStringMap from a
+// StringMapI interface object
+func NewSafeStringMapFrom(i StringMapI) *SafeStringMap {
+	return generic.NewSafeMapFrom[string, string](i)
+}
+
+// NewSafeStringMapFromMap creates a new SafeStringMap from a
+// GO map[string]string object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewSafeStringMapFromMap(i map[string]string) *SafeStringMap {
+	return generic.NewSafeMapFromMap[string, string](i)
+}
+
+func init() {
+	gob.Register(new (SafeStringMap))
+}
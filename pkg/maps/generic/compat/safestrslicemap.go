@@ -0,0 +1,33 @@
+// Code generated by gengen. DO NOT EDIT.
+
+package compat
+
+import (
+	"encoding/gob"
+	"github.com/goradd/gengen/pkg/maps/generic"
+)
+
+// SafeStringSliceMap is a generic.SafeSliceMap that maps string's to string's.
+type SafeStringSliceMap = generic.SafeSliceMap[string, string]
+
+// NewSafeStringSliceMap creates a new map that maps string's to string's.
+func NewSafeStringSliceMap() *SafeStringSliceMap {
+	return generic.NewSafeSliceMap[string, string]()
+}
+
+// NewSafeStringSliceMapFrom creates a new SafeStringSliceMap from a
+// StringMapI interface object
+func NewSafeStringSliceMapFrom(i StringMapI) *SafeStringSliceMap {
+	return generic.NewSafeSliceMapFrom[string, string](i)
+}
+
+// NewSafeStringSliceMapFromMap creates a new SafeStringSliceMap from a
+// GO map[string]string object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewSafeStringSliceMapFromMap(i map[string]string) *SafeStringSliceMap {
+	return generic.NewSafeSliceMapFromMap[string, string](i)
+}
+
+func init() {
+	gob.Register(new (SafeStringSliceMap))
+}
@@ -0,0 +1,33 @@
+// Code generated by gengen. DO NOT EDIT.
+
+package compat
+
+import (
+	"encoding/gob"
+	"github.com/goradd/gengen/pkg/maps/generic"
+)
+
+// StringMap is a generic.Map that maps string's to string's.
+type StringMap = generic.Map[string, string]
+
+// NewStringMap creates a new map that maps string's to string's.
+func NewStringMap() *StringMap {
+	return generic.NewMap[string, string]()
+}
+
+// NewStringMapFrom creates a new StringMap from a
+// StringMapI interface object
+func NewStringMapFrom(i StringMapI) *StringMap {
+	return generic.NewMapFrom[string, string](i)
+}
+
+// NewStringMapFromMap creates a new StringMap from a
+// GO map[string]string object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewStringMapFromMap(i map[string]string) *StringMap {
+	return generic.NewMapFromMap[string, string](i)
+}
+
+func init() {
+	gob.Register(new (StringMap))
+}
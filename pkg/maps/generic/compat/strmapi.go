@@ -0,0 +1,12 @@
+// Code generated by gengen. DO NOT EDIT.
+
+package compat
+
+import "github.com/goradd/gengen/pkg/maps/generic"
+
+type StringGetter = generic.Getter[string, string]
+type StringLoader = generic.Loader[string, string]
+type StringSetter = generic.Setter[string, string]
+
+// The StringMapI interface provides a common interface to the many kinds of similar map objects.
+type StringMapI = generic.MapI[string, string]
@@ -0,0 +1,33 @@
+// Code generated by gengen. DO NOT EDIT.
+
+package compat
+
+import (
+	"encoding/gob"
+	"github.com/goradd/gengen/pkg/maps/generic"
+)
+
+// StringSliceMap is a generic.SliceMap that maps string's to string's.
+type StringSliceMap = generic.SliceMap[string, string]
+
+// NewStringSliceMap creates a new map that maps string's to string's.
+func NewStringSliceMap() *StringSliceMap {
+	return generic.NewSliceMap[string, string]()
+}
+
+// NewStringSliceMapFrom creates a new StringSliceMap from a
+// StringMapI interface object
+func NewStringSliceMapFrom(i StringMapI) *StringSliceMap {
+	return generic.NewSliceMapFrom[string, string](i)
+}
+
+// NewStringSliceMapFromMap creates a new StringSliceMap from a
+// GO map[string]string object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewStringSliceMapFromMap(i map[string]string) *StringSliceMap {
+	return generic.NewSliceMapFromMap[string, string](i)
+}
+
+func init() {
+	gob.Register(new (StringSliceMap))
+}
@@ -0,0 +1,341 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Map maps keys of type K to values of type V.
+// This version is not safe for concurrent use.
+// A zero value is ready for use, but you may not copy it after first using it.
+type Map[K comparable, V any] struct {
+	items map[K]V
+}
+
+// NewMap creates a new map that maps K's to V's.
+func NewMap[K comparable, V any]() *Map[K, V] {
+	return new(Map[K, V])
+}
+
+// NewMapFrom creates a new Map from a
+// MapI interface object
+func NewMapFrom[K comparable, V any](i MapI[K, V]) *Map[K, V] {
+	m := NewMap[K, V]()
+	m.Merge(i)
+	return m
+}
+
+// NewMapFromMap creates a new Map from a
+// GO map[K]V object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewMapFromMap[K comparable, V any](i map[K]V) *Map[K, V] {
+	m := NewMap[K, V]()
+	m.items = i
+	return m
+}
+
+// Clear resets the map to an empty map
+func (o *Map[K, V]) Clear() {
+	if o == nil {
+		return
+	}
+	o.items = nil
+}
+
+// SetChanged sets the key to the value and returns a boolean indicating whether doing this caused
+// the map to change. It will return true if the key did not first exist, or if the value associated
+// with the key was different than the new value.
+func (o *Map[K, V]) SetChanged(key K, val V) (changed bool) {
+	if o == nil {
+		panic("The map must be created before being used.")
+	}
+	if o.items == nil {
+		o.items = make(map[K]V)
+	}
+
+	if oldVal, ok := o.items[key]; !ok || !equal(oldVal, val) {
+		o.items[key] = val
+		changed = true
+	}
+	return
+}
+
+// Set sets the key to the given value
+func (o *Map[K, V]) Set(key K, val V) {
+	if o == nil {
+		panic("The map must be initialized before being used.")
+	}
+	if o.items == nil {
+		o.items = make(map[K]V)
+	}
+
+	o.items[key] = val
+}
+
+// Get returns the value based on its key. If it does not exist, the zero value will be returned.
+func (o *Map[K, V]) Get(key K) (val V) {
+	val, _ = o.Load(key)
+	return
+}
+
+// Load returns the value based on its key, and a boolean indicating whether it exists in the map.
+// This is the same interface as sync.Map.Load()
+func (o *Map[K, V]) Load(key K) (val V, ok bool) {
+	if o == nil {
+		return
+	}
+	if o.items != nil {
+		val, ok = o.items[key]
+	}
+	return
+}
+
+// LoadString returns the value of the key as a string, and whether it exists and is a string.
+func (o *Map[K, V]) LoadString(key K) (val string, ok bool) {
+	return loadAs[string, K, V](o, key)
+}
+
+// LoadInt returns the value of the key as an int, and whether it exists and is an int.
+func (o *Map[K, V]) LoadInt(key K) (val int, ok bool) {
+	return loadAs[int, K, V](o, key)
+}
+
+// LoadBool returns the value of the key as a bool, and whether it exists and is a bool.
+func (o *Map[K, V]) LoadBool(key K) (val bool, ok bool) {
+	return loadAs[bool, K, V](o, key)
+}
+
+// LoadFloat64 returns the value of the key as a float64, and whether it exists and is a float64.
+func (o *Map[K, V]) LoadFloat64(key K) (val float64, ok bool) {
+	return loadAs[float64, K, V](o, key)
+}
+
+// Delete removes the key from the map. If the key does not exist, nothing happens.
+func (o *Map[K, V]) Delete(key K) {
+	if o == nil {
+		return
+	}
+	if o.items != nil {
+		delete(o.items, key)
+	}
+}
+
+// Has returns true if the given key exists in the map.
+func (o *Map[K, V]) Has(key K) (exists bool) {
+	if o == nil {
+		return
+	}
+	if o.items != nil {
+		_, exists = o.items[key]
+	}
+	return
+}
+
+// Is returns true if the given key exists in the map and has the given value.
+func (o *Map[K, V]) Is(key K, val V) (is bool) {
+	if o == nil {
+		return
+	}
+
+	var v V
+	if o.items != nil {
+		v, is = o.items[key]
+	}
+	return is && equal(v, val)
+}
+
+// Values returns a slice of the values. It will return a nil slice if the map is empty.
+// Multiple calls to Values will result in the same list of values, but may be in a different order.
+func (o *Map[K, V]) Values() (vals []V) {
+	if o == nil {
+		return
+	}
+	if len(o.items) > 0 {
+		vals = make([]V, 0, len(o.items))
+		for _, v := range o.items {
+			vals = append(vals, v)
+		}
+	}
+	return
+}
+
+// Keys returns a slice of the keys. It will return a nil slice if the map is empty.
+// Multiple calls to Keys will result in the same list of keys, but may be in a different order.
+func (o *Map[K, V]) Keys() (keys []K) {
+	if o == nil {
+		return
+	}
+	if len(o.items) > 0 {
+		keys = make([]K, 0, len(o.items))
+		for k := range o.items {
+			keys = append(keys, k)
+		}
+	}
+	return
+}
+
+// Len returns the number of items in the map
+func (o *Map[K, V]) Len() (l int) {
+	if o == nil {
+		return
+	}
+	return len(o.items)
+}
+
+// Range will call the given function with every key and value in the map.
+// If f returns false, it stops the iteration. This pattern is taken from sync.Map.
+func (o *Map[K, V]) Range(f func(key K, value V) bool) {
+	if o == nil {
+		return
+	}
+
+	for k, v := range o.items {
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
+// Merge merges the given  map with the current one. The given one takes precedent on collisions.
+func (o *Map[K, V]) Merge(i MapI[K, V]) {
+	if i == nil {
+		return
+	}
+
+	if o == nil {
+		panic("The map must be created before being used.")
+	}
+
+	if o.items == nil {
+		o.items = make(map[K]V, i.Len())
+	}
+	i.Range(func(k K, v V) bool {
+		o.items[k] = v
+		return true
+	})
+}
+
+// MergeMap merges the given standard map with the current one. The given one takes precedent on collisions.
+func (o *Map[K, V]) MergeMap(m map[K]V) {
+	if m == nil {
+		return
+	}
+
+	if o == nil {
+		panic("The map must be created before being used.")
+	}
+
+	if o.items == nil {
+		o.items = make(map[K]V, len(m))
+	}
+	for k, v := range m {
+		o.items[k] = v
+	}
+}
+
+// Equals returns true if all the keys in the given map exist in this map, and the values are the same
+func (o *Map[K, V]) Equals(i MapI[K, V]) bool {
+	return equals[K, V](o, i)
+}
+
+// equals returns true if the maps have the same keys, with the same values, in any order.
+func equals[K comparable, V any](m interface {
+	Loader[K, V]
+	Len() int
+}, i MapI[K, V]) bool {
+	l := 0
+	if i != nil {
+		l = i.Len()
+	}
+	if m.Len() != l {
+		return false
+	} else if l == 0 { // both are zero
+		return true
+	}
+	ret := true
+	i.Range(func(k K, v V) bool {
+		if v2, ok := m.Load(k); !ok || !equal(v2, v) {
+			ret = false
+			return false // stop iterating
+		}
+		return true
+	})
+	return ret
+}
+
+// Copy will make a copy of the map and a copy of the underlying data.
+// If the keys or values have a Copy method that returns their own type, it is called to deep copy them.
+func (o *Map[K, V]) Copy() MapI[K, V] {
+	cp := NewMap[K, V]()
+
+	o.Range(func(key K, value V) bool {
+		cp.Set(copyOf(key), copyOf(value))
+		return true
+	})
+	return cp
+}
+
+// MarshalBinary implements the BinaryMarshaler interface to convert the map to a byte stream.
+func (o *Map[K, V]) MarshalBinary() ([]byte, error) {
+	var b bytes.Buffer
+
+	enc := gob.NewEncoder(&b)
+	err := enc.Encode(o.items)
+	return b.Bytes(), err
+}
+
+// UnmarshalBinary implements the BinaryUnmarshaler interface to convert a byte stream to a
+// Map
+func (o *Map[K, V]) UnmarshalBinary(data []byte) (err error) {
+	var v map[K]V
+
+	b := bytes.NewBuffer(data)
+	dec := gob.NewDecoder(b)
+	if err = dec.Decode(&v); err == nil {
+		o.items = v
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface to convert the map into a JSON object.
+func (o *Map[K, V]) MarshalJSON() (out []byte, err error) {
+	return json.Marshal(o.items)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface to convert a json object to a Map.
+// The JSON must start with an object.
+func (o *Map[K, V]) UnmarshalJSON(in []byte) (err error) {
+	var v map[K]V
+	if err = json.Unmarshal(in, &v); err == nil {
+		o.items = v
+	}
+	return
+}
+
+func (o *Map[K, V]) IsNil() bool {
+	return o == nil
+}
+
+// String returns the map as a string, with its keys in order.
+func (o *Map[K, V]) String() string {
+	keys := o.Keys()
+	sortKeys(keys)
+	return format(keys, o.Get)
+}
+
+// format returns the keys and their values as a string, in the style of a go map literal.
+func format[K comparable, V any](keys []K, get func(K) V) string {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `%#v:%#v`, k, get(k))
+	}
+	b.WriteString("}")
+	return b.String()
+}
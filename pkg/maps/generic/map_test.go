@@ -0,0 +1,60 @@
+package generic
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+}
+
+func TestMapStruct(t *testing.T) {
+	m := NewSafeMap[point, string]()
+	m.Set(point{1, 2}, "a")
+	m.Set(point{0, 3}, "b")
+
+	if !m.Is(point{1, 2}, "a") || m.Is(point{1, 2}, "b") {
+		t.Error("Expected Is to compare the value")
+	}
+	// keys that cannot be compared with < print in the order of how they print
+	if s := m.String(); s != `{generic.point{X:0, Y:3}:"b",generic.point{X:1, Y:2}:"a"}` {
+		t.Errorf("Unexpected String %s", s)
+	}
+
+	m2 := NewMapFrom[point, string](m)
+	if !m2.Equals(m) {
+		t.Error("Expected NewMapFrom to copy the map")
+	}
+	m2.Delete(point{0, 3})
+	if m2.Equals(m) || m.Len() != 2 {
+		t.Error("Expected the maps to be independent")
+	}
+}
+
+func TestMapInterface(t *testing.T) {
+	var m Map[string, interface{}]
+	if err := json.Unmarshal([]byte(`{"a":"b","c":1,"d":true}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := m.LoadString("a"); !ok || s != "b" {
+		t.Errorf("Expected LoadString to find b, got %q", s)
+	}
+	if _, ok := m.LoadInt("c"); ok {
+		t.Error("Expected LoadInt to fail on a JSON number, which is a float64")
+	}
+	if f, ok := m.LoadFloat64("c"); !ok || f != 1 {
+		t.Errorf("Expected LoadFloat64 to find 1, got %v", f)
+	}
+	if b, ok := m.LoadBool("d"); !ok || !b {
+		t.Error("Expected LoadBool to find true")
+	}
+
+	var m2 MapI[string, interface{}] = NewSliceMapFromMap(map[string]interface{}{"a": "b", "c": 1.0, "d": true})
+	if !m.Equals(m2) {
+		t.Error("Expected maps of different kinds with the same items to be equal")
+	}
+	if !NewMap[string, interface{}]().Equals(nil) {
+		t.Error("Expected an empty map to equal nil")
+	}
+}
@@ -0,0 +1,44 @@
+// Package generic has the maps of the maps package as generic types, which work with any key and value types.
+// They behave like the types that the map templates of the Library generate, which makes it possible to replace
+// a generated type with an alias of a generic one, like
+//
+//	type StringSliceMap = generic.SliceMap[string, string]
+//
+// The map templates produce such aliases, and the functions that create the maps, when their generic value is true.
+//
+// The generated types only have the methods that their key and value types support, like SortByValues for values
+// that can be compared with <. The generic types have all of them, and the methods that the types do not support
+// panic, the way the methods of a generated map with interface{} values do when the values do not support them.
+package generic
+
+// A Getter gets values from a map.
+type Getter[K comparable, V any] interface {
+	Get(key K) (val V)
+}
+
+// A Loader loads values from a map, and reports whether they exist.
+type Loader[K comparable, V any] interface {
+	Load(key K) (val V, ok bool)
+}
+
+// A Setter sets values in a map.
+type Setter[K comparable, V any] interface {
+	Set(K, V)
+}
+
+// The MapI interface provides a common interface to the many kinds of similar map objects.
+//
+// Most functions that change the map are omitted so that you can wrap the map in additional functionality that might
+// use Set or SetChanged. If you want to use them in an interface setting, you can create your own interface
+// that includes them.
+type MapI[K comparable, V any] interface {
+	Get(key K) (val V)
+	Has(key K) (exists bool)
+	Values() []V
+	Keys() []K
+	Len() int
+	// Range will iterate over the keys and values in the map. Pattern is taken from sync.Map
+	Range(f func(key K, value V) bool)
+	Merge(i MapI[K, V])
+	String() string
+}
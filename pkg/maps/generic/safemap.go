@@ -0,0 +1,278 @@
+package generic
+
+import (
+	"sync"
+)
+
+// SafeMap maps keys of type K to values of type V.
+// This version is safe for concurrent use.
+// A zero value is ready for use, but you may not copy it after first using it.
+type SafeMap[K comparable, V any] struct {
+	sync.RWMutex
+	m Map[K, V]
+}
+
+// NewSafeMap creates a new map that maps K's to V's.
+func NewSafeMap[K comparable, V any]() *SafeMap[K, V] {
+	return new(SafeMap[K, V])
+}
+
+// NewSafeMapFrom creates a new SafeMap from a
+// MapI interface object
+func NewSafeMapFrom[K comparable, V any](i MapI[K, V]) *SafeMap[K, V] {
+	m := NewSafeMap[K, V]()
+	m.Merge(i)
+	return m
+}
+
+// NewSafeMapFromMap creates a new SafeMap from a
+// GO map[K]V object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewSafeMapFromMap[K comparable, V any](i map[K]V) *SafeMap[K, V] {
+	m := NewSafeMap[K, V]()
+	m.m.items = i
+	return m
+}
+
+// Clear resets the map to an empty map
+func (o *SafeMap[K, V]) Clear() {
+	if o == nil {
+		return
+	}
+	o.Lock()
+	o.m.Clear()
+	o.Unlock()
+}
+
+// SetChanged sets the key to the value and returns a boolean indicating whether doing this caused
+// the map to change. It will return true if the key did not first exist, or if the value associated
+// with the key was different than the new value.
+func (o *SafeMap[K, V]) SetChanged(key K, val V) (changed bool) {
+	if o == nil {
+		panic("The map must be created before being used.")
+	}
+	o.Lock()
+	defer o.Unlock()
+	return o.m.SetChanged(key, val)
+}
+
+// Set sets the key to the given value
+func (o *SafeMap[K, V]) Set(key K, val V) {
+	if o == nil {
+		panic("The map must be initialized before being used.")
+	}
+	o.Lock()
+	o.m.Set(key, val)
+	o.Unlock()
+}
+
+// Get returns the value based on its key. If it does not exist, the zero value will be returned.
+func (o *SafeMap[K, V]) Get(key K) (val V) {
+	val, _ = o.Load(key)
+	return
+}
+
+// Load returns the value based on its key, and a boolean indicating whether it exists in the map.
+// This is the same interface as sync.Map.Load()
+func (o *SafeMap[K, V]) Load(key K) (val V, ok bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Load(key)
+}
+
+// LoadString returns the value of the key as a string, and whether it exists and is a string.
+func (o *SafeMap[K, V]) LoadString(key K) (val string, ok bool) {
+	return loadAs[string, K, V](o, key)
+}
+
+// LoadInt returns the value of the key as an int, and whether it exists and is an int.
+func (o *SafeMap[K, V]) LoadInt(key K) (val int, ok bool) {
+	return loadAs[int, K, V](o, key)
+}
+
+// LoadBool returns the value of the key as a bool, and whether it exists and is a bool.
+func (o *SafeMap[K, V]) LoadBool(key K) (val bool, ok bool) {
+	return loadAs[bool, K, V](o, key)
+}
+
+// LoadFloat64 returns the value of the key as a float64, and whether it exists and is a float64.
+func (o *SafeMap[K, V]) LoadFloat64(key K) (val float64, ok bool) {
+	return loadAs[float64, K, V](o, key)
+}
+
+// Delete removes the key from the map. If the key does not exist, nothing happens.
+func (o *SafeMap[K, V]) Delete(key K) {
+	if o == nil {
+		return
+	}
+	o.Lock()
+	o.m.Delete(key)
+	o.Unlock()
+}
+
+// Has returns true if the given key exists in the map.
+func (o *SafeMap[K, V]) Has(key K) (exists bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Has(key)
+}
+
+// Is returns true if the given key exists in the map and has the given value.
+func (o *SafeMap[K, V]) Is(key K, val V) (is bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Is(key, val)
+}
+
+// Values returns a slice of the values. It will return a nil slice if the map is empty.
+// Multiple calls to Values will result in the same list of values, but may be in a different order.
+func (o *SafeMap[K, V]) Values() (vals []V) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Values()
+}
+
+// Keys returns a slice of the keys. It will return a nil slice if the map is empty.
+// Multiple calls to Keys will result in the same list of keys, but may be in a different order.
+func (o *SafeMap[K, V]) Keys() (keys []K) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Keys()
+}
+
+// Len returns the number of items in the map
+func (o *SafeMap[K, V]) Len() (l int) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Len()
+}
+
+// Range will call the given function with every key and value in the map.
+// If f returns false, it stops the iteration. This pattern is taken from sync.Map.
+// During this process, the map will be locked, so do not pass a function that will take significant amounts of time.
+func (o *SafeMap[K, V]) Range(f func(key K, value V) bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	o.m.Range(f)
+}
+
+// Merge merges the given  map with the current one. The given one takes precedent on collisions.
+func (o *SafeMap[K, V]) Merge(i MapI[K, V]) {
+	if i == nil {
+		return
+	}
+	if o == nil {
+		panic("The map must be created before being used.")
+	}
+	o.Lock()
+	defer o.Unlock()
+	o.m.Merge(i)
+}
+
+// MergeMap merges the given standard map with the current one. The given one takes precedent on collisions.
+func (o *SafeMap[K, V]) MergeMap(m map[K]V) {
+	if m == nil {
+		return
+	}
+	if o == nil {
+		panic("The map must be created before being used.")
+	}
+	o.Lock()
+	defer o.Unlock()
+	o.m.MergeMap(m)
+}
+
+// Equals returns true if all the keys in the given map exist in this map, and the values are the same
+func (o *SafeMap[K, V]) Equals(i MapI[K, V]) bool {
+	if o == nil {
+		return equals[K, V]((*Map[K, V])(nil), i)
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return equals[K, V](&o.m, i)
+}
+
+// Copy will make a copy of the map and a copy of the underlying data.
+// If the keys or values have a Copy method that returns their own type, it is called to deep copy them.
+func (o *SafeMap[K, V]) Copy() MapI[K, V] {
+	cp := NewSafeMap[K, V]()
+
+	o.Range(func(key K, value V) bool {
+		cp.m.Set(copyOf(key), copyOf(value))
+		return true
+	})
+	return cp
+}
+
+// MarshalBinary implements the BinaryMarshaler interface to convert the map to a byte stream.
+func (o *SafeMap[K, V]) MarshalBinary() ([]byte, error) {
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.MarshalBinary()
+}
+
+// UnmarshalBinary implements the BinaryUnmarshaler interface to convert a byte stream to a
+// SafeMap
+func (o *SafeMap[K, V]) UnmarshalBinary(data []byte) (err error) {
+	var m Map[K, V]
+	if err = m.UnmarshalBinary(data); err == nil {
+		o.Lock()
+		o.m = m
+		o.Unlock()
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface to convert the map into a JSON object.
+func (o *SafeMap[K, V]) MarshalJSON() (out []byte, err error) {
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface to convert a json object to a SafeMap.
+// The JSON must start with an object.
+func (o *SafeMap[K, V]) UnmarshalJSON(in []byte) (err error) {
+	var m Map[K, V]
+	if err = m.UnmarshalJSON(in); err == nil {
+		o.Lock()
+		o.m = m
+		o.Unlock()
+	}
+	return
+}
+
+func (o *SafeMap[K, V]) IsNil() bool {
+	return o == nil
+}
+
+// String returns the map as a string, with its keys in order.
+func (o *SafeMap[K, V]) String() string {
+	if o == nil {
+		return "{}"
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.String()
+}
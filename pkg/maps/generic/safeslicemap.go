@@ -0,0 +1,340 @@
+package generic
+
+import (
+	"sync"
+)
+
+// A SafeSliceMap combines a map with a slice so that you can range over a
+// map in a predictable order. By default, the order will be the same order that items were inserted,
+// i.e. a FIFO list. This is similar to how PHP arrays work.
+// SafeSliceMap implements the sort interface so you can change the order
+// before ranging over the values if desired.
+// It is safe for concurrent use.
+// The zero of this is usable immediately.
+// The SafeSliceMap satisfies the MapI interface.
+type SafeSliceMap[K comparable, V any] struct {
+	sync.RWMutex
+	m SliceMap[K, V]
+}
+
+// NewSafeSliceMap creates a new map that maps K's to V's.
+func NewSafeSliceMap[K comparable, V any]() *SafeSliceMap[K, V] {
+	return new(SafeSliceMap[K, V])
+}
+
+// NewSafeSliceMapFrom creates a new SafeSliceMap from a
+// MapI interface object
+func NewSafeSliceMapFrom[K comparable, V any](i MapI[K, V]) *SafeSliceMap[K, V] {
+	m := new(SafeSliceMap[K, V])
+	m.Merge(i)
+	return m
+}
+
+// NewSafeSliceMapFromMap creates a new SafeSliceMap from a
+// GO map[K]V object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewSafeSliceMapFromMap[K comparable, V any](i map[K]V) *SafeSliceMap[K, V] {
+	m := NewSafeSliceMap[K, V]()
+	m.m = *NewSliceMapFromMap(i)
+	return m
+}
+
+// SetSortFunc sets the sort function which will determine the order of the items in the map
+// on an ongoing basis. Normally, items will iterate in the order they were added.
+// The sort function is a Less function, that returns true when item 1 is "less" than item 2.
+// The sort function receives both the keys and values, so it can use either to decide how to sort.
+func (o *SafeSliceMap[K, V]) SetSortFunc(f func(key1, key2 K, val1, val2 V) bool) *SafeSliceMap[K, V] {
+	o.Lock()
+	o.m.SetSortFunc(f)
+	o.Unlock()
+	return o
+}
+
+// SortByKeys sets up the map to have its sort order sort by keys, lowest to highest.
+// It panics if the keys cannot be compared with <.
+func (o *SafeSliceMap[K, V]) SortByKeys() *SafeSliceMap[K, V] {
+	o.SetSortFunc(keySort[K, V])
+	return o
+}
+
+// SortByValues sets up the map to have its sort order sort by values, lowest to highest.
+// It panics if the values cannot be compared with <.
+func (o *SafeSliceMap[K, V]) SortByValues() {
+	o.SetSortFunc(valueSort[K, V])
+}
+
+// SetChanged sets the value.
+// It returns true if something in the map changed. If the key
+// was already in the map, and you have not provided a sort function,
+// the order will not change, but the value will be replaced. If you wanted the
+// order to change, you must Delete then call SetChanged. If you have previously set a sort function,
+// the order will be updated.
+func (o *SafeSliceMap[K, V]) SetChanged(key K, val V) (changed bool) {
+	if o == nil {
+		panic("You must initialize the map before using it.")
+	}
+	o.Lock()
+	defer o.Unlock()
+	return o.m.SetChanged(key, val)
+}
+
+// Set sets the given key to the given value.
+// If the key already exists, the range order will not change.
+func (o *SafeSliceMap[K, V]) Set(key K, val V) {
+	if o == nil {
+		panic("You must initialize the map before using it.")
+	}
+	o.Lock()
+	o.m.Set(key, val)
+	o.Unlock()
+}
+
+// SetAt sets the given key to the given value, but also inserts it at the index specified.  If the index is bigger than
+// the length, it puts it at the end. Negative indexes are backwards from the end.
+func (o *SafeSliceMap[K, V]) SetAt(index int, key K, val V) {
+	if o == nil {
+		panic("You must initialize the map before using it.")
+	}
+	o.Lock()
+	defer o.Unlock()
+	o.m.SetAt(index, key, val)
+}
+
+// Delete removes the item with the given key.
+func (o *SafeSliceMap[K, V]) Delete(key K) {
+	if o == nil {
+		return
+	}
+	o.Lock()
+	o.m.Delete(key)
+	o.Unlock()
+}
+
+// Get returns the value based on its key. If the key does not exist, an empty value is returned.
+func (o *SafeSliceMap[K, V]) Get(key K) (val V) {
+	val, _ = o.Load(key)
+	return
+}
+
+// Load returns the value based on its key, and a boolean indicating whether it exists in the map.
+// This is the same interface as sync.Map.Load()
+func (o *SafeSliceMap[K, V]) Load(key K) (val V, ok bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Load(key)
+}
+
+// LoadString returns the value of the key as a string, and whether it exists and is a string.
+func (o *SafeSliceMap[K, V]) LoadString(key K) (val string, ok bool) {
+	return loadAs[string, K, V](o, key)
+}
+
+// LoadInt returns the value of the key as an int, and whether it exists and is an int.
+func (o *SafeSliceMap[K, V]) LoadInt(key K) (val int, ok bool) {
+	return loadAs[int, K, V](o, key)
+}
+
+// LoadBool returns the value of the key as a bool, and whether it exists and is a bool.
+func (o *SafeSliceMap[K, V]) LoadBool(key K) (val bool, ok bool) {
+	return loadAs[bool, K, V](o, key)
+}
+
+// LoadFloat64 returns the value of the key as a float64, and whether it exists and is a float64.
+func (o *SafeSliceMap[K, V]) LoadFloat64(key K) (val float64, ok bool) {
+	return loadAs[float64, K, V](o, key)
+}
+
+// Has returns true if the given key exists in the map.
+func (o *SafeSliceMap[K, V]) Has(key K) (ok bool) {
+	if o == nil {
+		return false
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Has(key)
+}
+
+// Is returns true if the given key exists in the map and has the given value.
+func (o *SafeSliceMap[K, V]) Is(key K, val V) (is bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Is(key, val)
+}
+
+// GetAt returns the value based on its position. If the position is out of bounds, an empty value is returned.
+func (o *SafeSliceMap[K, V]) GetAt(position int) (val V) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.GetAt(position)
+}
+
+// GetKeyAt returns the key based on its position. If the position is out of bounds, an empty value is returned.
+func (o *SafeSliceMap[K, V]) GetKeyAt(position int) (key K) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.GetKeyAt(position)
+}
+
+// Values returns a slice of the values in the order they were added or sorted.
+func (o *SafeSliceMap[K, V]) Values() (vals []V) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Values()
+}
+
+// Keys returns the keys of the map, in the order they were added or sorted
+func (o *SafeSliceMap[K, V]) Keys() (keys []K) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Keys()
+}
+
+// Len returns the number of items in the map
+func (o *SafeSliceMap[K, V]) Len() int {
+	if o == nil {
+		return 0
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.Len()
+}
+
+// Copy will make a copy of the map and a copy of the underlying data.
+// If the keys or values have a Copy method that returns their own type, it is called to deep copy them.
+func (o *SafeSliceMap[K, V]) Copy() *SafeSliceMap[K, V] {
+	cp := NewSafeSliceMap[K, V]()
+	if o != nil {
+		o.RLock()
+		cp.m = *o.m.Copy()
+		o.RUnlock()
+	}
+	return cp
+}
+
+// MarshalBinary implements the BinaryMarshaler interface to convert the map to a byte stream.
+// If you are using a sort function, you must save and restore the sort function in a separate operation
+// since functions are not serializable.
+func (o *SafeSliceMap[K, V]) MarshalBinary() (data []byte, err error) {
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.MarshalBinary()
+}
+
+// UnmarshalBinary implements the BinaryUnmarshaler interface to convert a byte stream to a
+// SafeSliceMap
+func (o *SafeSliceMap[K, V]) UnmarshalBinary(data []byte) (err error) {
+	var m SliceMap[K, V]
+	if err = m.UnmarshalBinary(data); err == nil {
+		o.Lock()
+		o.m.items, o.m.order = m.items, m.order
+		o.Unlock()
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface to convert the map into a JSON object.
+func (o *SafeSliceMap[K, V]) MarshalJSON() (data []byte, err error) {
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface to convert a json object to a SafeSliceMap.
+// The JSON must start with an object.
+func (o *SafeSliceMap[K, V]) UnmarshalJSON(data []byte) (err error) {
+	var m SliceMap[K, V]
+	if err = m.UnmarshalJSON(data); err == nil {
+		o.Lock()
+		o.m.items, o.m.order = m.items, m.order
+		o.Unlock()
+	}
+	return
+}
+
+// Merge the given map into the current one
+func (o *SafeSliceMap[K, V]) Merge(i MapI[K, V]) {
+	if i != nil {
+		o.Lock()
+		defer o.Unlock()
+		o.m.Merge(i)
+	}
+}
+
+// MergeMap merges the given standard map with the current one. The given one takes precedent on collisions.
+func (o *SafeSliceMap[K, V]) MergeMap(m map[K]V) {
+	if m != nil {
+		o.Lock()
+		defer o.Unlock()
+		o.m.MergeMap(m)
+	}
+}
+
+// Range will call the given function with every key and value in the order
+// they were placed in the map, or in if you sorted the map, in your custom order.
+// If f returns false, it stops the iteration. This pattern is taken from sync.Map.
+// During this process, the map will be locked, so do not pass a function that will take significant amounts of time.
+func (o *SafeSliceMap[K, V]) Range(f func(key K, value V) bool) {
+	if o == nil {
+		return
+	}
+	o.RLock()
+	defer o.RUnlock()
+	o.m.Range(f)
+}
+
+// Equals returns true if the map equals the given map, paying attention only to the content of the
+// map and not the order.
+func (o *SafeSliceMap[K, V]) Equals(i MapI[K, V]) bool {
+	if o == nil {
+		return equals[K, V]((*SliceMap[K, V])(nil), i)
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return equals[K, V](&o.m, i)
+}
+
+func (o *SafeSliceMap[K, V]) Clear() {
+	if o == nil {
+		return
+	}
+	o.Lock()
+	o.m.Clear()
+	o.Unlock()
+}
+
+func (o *SafeSliceMap[K, V]) IsNil() bool {
+	return o == nil
+}
+
+// String returns the map as a string, in its order.
+func (o *SafeSliceMap[K, V]) String() string {
+	if o == nil {
+		return "{}"
+	}
+	o.RLock()
+	defer o.RUnlock()
+	return o.m.String()
+}
+
+// Join is just like strings.Join, with the values printed like fmt.Sprint prints them.
+func (o *SafeSliceMap[K, V]) Join(glue string) string {
+	return join(o.Values(), glue)
+}
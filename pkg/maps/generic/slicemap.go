@@ -0,0 +1,461 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A SliceMap combines a map with a slice so that you can range over a
+// map in a predictable order. By default, the order will be the same order that items were inserted,
+// i.e. a FIFO list. This is similar to how PHP arrays work.
+// SliceMap implements the sort interface so you can change the order
+// before ranging over the values if desired.
+// It is NOT safe for concurrent use.
+// The zero of this is usable immediately.
+// The SliceMap satisfies the MapI interface.
+type SliceMap[K comparable, V any] struct {
+	items map[K]V
+	order []K
+	lessF func(key1, key2 K, val1, val2 V) bool
+}
+
+// NewSliceMap creates a new map that maps K's to V's.
+func NewSliceMap[K comparable, V any]() *SliceMap[K, V] {
+	return new(SliceMap[K, V])
+}
+
+// NewSliceMapFrom creates a new SliceMap from a
+// MapI interface object
+func NewSliceMapFrom[K comparable, V any](i MapI[K, V]) *SliceMap[K, V] {
+	m := new(SliceMap[K, V])
+	m.Merge(i)
+	return m
+}
+
+// NewSliceMapFromMap creates a new SliceMap from a
+// GO map[K]V object. Note that this will pass control of the given map to the
+// new object. After you do this, DO NOT change the original map.
+func NewSliceMapFromMap[K comparable, V any](i map[K]V) *SliceMap[K, V] {
+	m := NewSliceMap[K, V]()
+	m.items = i
+	m.order = make([]K, 0, len(i))
+	for k := range m.items {
+		m.order = append(m.order, k)
+	}
+	return m
+}
+
+// SetSortFunc sets the sort function which will determine the order of the items in the map
+// on an ongoing basis. Normally, items will iterate in the order they were added.
+// The sort function is a Less function, that returns true when item 1 is "less" than item 2.
+// The sort function receives both the keys and values, so it can use either to decide how to sort.
+func (o *SliceMap[K, V]) SetSortFunc(f func(key1, key2 K, val1, val2 V) bool) *SliceMap[K, V] {
+	o.lessF = f
+	if f != nil && len(o.order) > 0 {
+		sort.Slice(o.order, func(i, j int) bool {
+			return f(o.order[i], o.order[j], o.items[o.order[i]], o.items[o.order[j]])
+		})
+	}
+	return o
+}
+
+// SortByKeys sets up the map to have its sort order sort by keys, lowest to highest.
+// It panics if the keys cannot be compared with <.
+func (o *SliceMap[K, V]) SortByKeys() *SliceMap[K, V] {
+	o.SetSortFunc(keySort[K, V])
+	return o
+}
+
+func keySort[K comparable, V any](key1, key2 K, val1, val2 V) bool {
+	return less(key1, key2)
+}
+
+// SortByValues sets up the map to have its sort order sort by values, lowest to highest.
+// It panics if the values cannot be compared with <.
+func (o *SliceMap[K, V]) SortByValues() {
+	o.SetSortFunc(valueSort[K, V])
+}
+
+func valueSort[K comparable, V any](key1, key2 K, val1, val2 V) bool {
+	return less(val1, val2)
+}
+
+// find returns the position of the key in the order, which has the value val.
+func (o *SliceMap[K, V]) find(key K, val V) int {
+	if o.lessF != nil {
+		loc := sort.Search(len(o.order), func(n int) bool {
+			return !o.lessF(o.order[n], key, o.items[o.order[n]], val)
+		})
+		// items that sort the same as the key can come before it
+		for ; loc < len(o.order); loc++ {
+			if o.order[loc] == key {
+				return loc
+			}
+		}
+	}
+	for i, k := range o.order {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert puts a key that is not in the order into it, where the sort function puts it, or at the end.
+func (o *SliceMap[K, V]) insert(key K, val V) {
+	if o.lessF == nil {
+		o.order = append(o.order, key)
+		return
+	}
+	loc := sort.Search(len(o.order), func(n int) bool {
+		return o.lessF(key, o.order[n], val, o.items[o.order[n]])
+	})
+	o.order = append(o.order, key)
+	copy(o.order[loc+1:], o.order[loc:])
+	o.order[loc] = key
+}
+
+// SetChanged sets the value.
+// It returns true if something in the map changed. If the key
+// was already in the map, and you have not provided a sort function,
+// the order will not change, but the value will be replaced. If you wanted the
+// order to change, you must Delete then call SetChanged. If you have previously set a sort function,
+// the order will be updated.
+func (o *SliceMap[K, V]) SetChanged(key K, val V) (changed bool) {
+	if o == nil {
+		panic("You must initialize the map before using it.")
+	}
+	if oldVal, ok := o.items[key]; !ok || !equal(oldVal, val) {
+		o.Set(key, val)
+		changed = true
+	}
+	return
+}
+
+// Set sets the given key to the given value.
+// If the key already exists, the range order will not change.
+func (o *SliceMap[K, V]) Set(key K, val V) {
+	if o == nil {
+		panic("You must initialize the map before using it.")
+	}
+	if o.items == nil {
+		o.items = make(map[K]V)
+	}
+
+	oldVal, ok := o.items[key]
+	if !ok {
+		o.insert(key, val)
+	} else if o.lessF != nil {
+		// the new value can sort the key to a different place
+		loc := o.find(key, oldVal)
+		o.order = append(o.order[:loc], o.order[loc+1:]...)
+		o.insert(key, val)
+	}
+	o.items[key] = val
+}
+
+// SetAt sets the given key to the given value, but also inserts it at the index specified.  If the index is bigger than
+// the length, it puts it at the end. Negative indexes are backwards from the end.
+func (o *SliceMap[K, V]) SetAt(index int, key K, val V) {
+	if o == nil {
+		panic("You must initialize the map before using it.")
+	}
+	if o.lessF != nil {
+		panic("You cannot use SetAt if you are also using a sort function.")
+	}
+
+	if index >= len(o.order) {
+		o.Set(key, val)
+		return
+	}
+
+	if _, ok := o.items[key]; !ok {
+		if index <= -len(o.items) {
+			index = 0
+		}
+		if index < 0 {
+			index = len(o.items) + index
+		}
+
+		var emptyKey K
+		o.order = append(o.order, emptyKey)
+		copy(o.order[index+1:], o.order[index:])
+		o.order[index] = key
+	}
+	o.items[key] = val
+}
+
+// Delete removes the item with the given key.
+func (o *SliceMap[K, V]) Delete(key K) {
+	if o == nil {
+		return
+	}
+	if val, ok := o.items[key]; ok {
+		loc := o.find(key, val)
+		o.order = append(o.order[:loc], o.order[loc+1:]...)
+		delete(o.items, key)
+	}
+}
+
+// Get returns the value based on its key. If the key does not exist, an empty value is returned.
+func (o *SliceMap[K, V]) Get(key K) (val V) {
+	val, _ = o.Load(key)
+	return
+}
+
+// Load returns the value based on its key, and a boolean indicating whether it exists in the map.
+// This is the same interface as sync.Map.Load()
+func (o *SliceMap[K, V]) Load(key K) (val V, ok bool) {
+	if o == nil {
+		return
+	}
+	if o.items != nil {
+		val, ok = o.items[key]
+	}
+	return
+}
+
+// LoadString returns the value of the key as a string, and whether it exists and is a string.
+func (o *SliceMap[K, V]) LoadString(key K) (val string, ok bool) {
+	return loadAs[string, K, V](o, key)
+}
+
+// LoadInt returns the value of the key as an int, and whether it exists and is an int.
+func (o *SliceMap[K, V]) LoadInt(key K) (val int, ok bool) {
+	return loadAs[int, K, V](o, key)
+}
+
+// LoadBool returns the value of the key as a bool, and whether it exists and is a bool.
+func (o *SliceMap[K, V]) LoadBool(key K) (val bool, ok bool) {
+	return loadAs[bool, K, V](o, key)
+}
+
+// LoadFloat64 returns the value of the key as a float64, and whether it exists and is a float64.
+func (o *SliceMap[K, V]) LoadFloat64(key K) (val float64, ok bool) {
+	return loadAs[float64, K, V](o, key)
+}
+
+// Has returns true if the given key exists in the map.
+func (o *SliceMap[K, V]) Has(key K) (ok bool) {
+	if o == nil {
+		return false
+	}
+	if o.items != nil {
+		_, ok = o.items[key]
+	}
+	return
+}
+
+// Is returns true if the given key exists in the map and has the given value.
+func (o *SliceMap[K, V]) Is(key K, val V) (is bool) {
+	if o == nil {
+		return
+	}
+
+	var v V
+	if o.items != nil {
+		v, is = o.items[key]
+	}
+	return is && equal(v, val)
+}
+
+// GetAt returns the value based on its position. If the position is out of bounds, an empty value is returned.
+func (o *SliceMap[K, V]) GetAt(position int) (val V) {
+	if o == nil {
+		return
+	}
+	if position < len(o.order) && position >= 0 {
+		val = o.items[o.order[position]]
+	}
+	return
+}
+
+// GetKeyAt returns the key based on its position. If the position is out of bounds, an empty value is returned.
+func (o *SliceMap[K, V]) GetKeyAt(position int) (key K) {
+	if o == nil {
+		return
+	}
+	if position < len(o.order) && position >= 0 {
+		key = o.order[position]
+	}
+	return
+}
+
+// Values returns a slice of the values in the order they were added or sorted.
+func (o *SliceMap[K, V]) Values() (vals []V) {
+	if o == nil {
+		return
+	}
+	if o.items != nil {
+		vals = make([]V, len(o.order))
+		for i, v := range o.order {
+			vals[i] = o.items[v]
+		}
+	}
+	return
+}
+
+// Keys returns the keys of the map, in the order they were added or sorted
+func (o *SliceMap[K, V]) Keys() (keys []K) {
+	if o == nil {
+		return
+	}
+	if len(o.order) != 0 {
+		keys = make([]K, len(o.order))
+		copy(keys, o.order)
+	}
+	return
+}
+
+// Len returns the number of items in the map
+func (o *SliceMap[K, V]) Len() int {
+	if o == nil {
+		return 0
+	}
+	return len(o.order)
+}
+
+// Copy will make a copy of the map and a copy of the underlying data.
+// If the keys or values have a Copy method that returns their own type, it is called to deep copy them.
+func (o *SliceMap[K, V]) Copy() *SliceMap[K, V] {
+	cp := NewSliceMap[K, V]()
+
+	o.Range(func(key K, value V) bool {
+		cp.Set(copyOf(key), copyOf(value))
+		return true
+	})
+	if o != nil {
+		cp.lessF = o.lessF
+	}
+	return cp
+}
+
+// MarshalBinary implements the BinaryMarshaler interface to convert the map to a byte stream.
+// If you are using a sort function, you must save and restore the sort function in a separate operation
+// since functions are not serializable.
+func (o *SliceMap[K, V]) MarshalBinary() (data []byte, err error) {
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err = encoder.Encode(o.items)
+	if err == nil {
+		err = encoder.Encode(o.order)
+	}
+	data = buf.Bytes()
+	return
+}
+
+// UnmarshalBinary implements the BinaryUnmarshaler interface to convert a byte stream to a
+// SliceMap
+func (o *SliceMap[K, V]) UnmarshalBinary(data []byte) (err error) {
+	var items map[K]V
+	var order []K
+
+	buf := bytes.NewBuffer(data)
+	dec := gob.NewDecoder(buf)
+	if err = dec.Decode(&items); err == nil {
+		err = dec.Decode(&order)
+	}
+
+	if err == nil {
+		o.items = items
+		o.order = order
+	}
+	return err
+}
+
+// MarshalJSON implements the json.Marshaler interface to convert the map into a JSON object.
+func (o *SliceMap[K, V]) MarshalJSON() (data []byte, err error) {
+	// Json objects are unordered
+	return json.Marshal(o.items)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface to convert a json object to a SliceMap.
+// The JSON must start with an object.
+func (o *SliceMap[K, V]) UnmarshalJSON(data []byte) (err error) {
+	var items map[K]V
+
+	if err = json.Unmarshal(data, &items); err == nil {
+		o.items = items
+		// Create a default order, since these are inherently unordered
+		o.order = make([]K, 0, len(o.items))
+		for k := range o.items {
+			o.order = append(o.order, k)
+		}
+	}
+	return
+}
+
+// Merge the given map into the current one
+func (o *SliceMap[K, V]) Merge(i MapI[K, V]) {
+	if i != nil {
+		i.Range(func(k K, v V) bool {
+			o.Set(k, v)
+			return true
+		})
+	}
+}
+
+// MergeMap merges the given standard map with the current one. The given one takes precedent on collisions.
+func (o *SliceMap[K, V]) MergeMap(m map[K]V) {
+	for k, v := range m {
+		o.Set(k, v)
+	}
+}
+
+// Range will call the given function with every key and value in the order
+// they were placed in the map, or in if you sorted the map, in your custom order.
+// If f returns false, it stops the iteration. This pattern is taken from sync.Map.
+func (o *SliceMap[K, V]) Range(f func(key K, value V) bool) {
+	if o == nil {
+		return
+	}
+	if o.items != nil {
+		for _, k := range o.order {
+			if !f(k, o.items[k]) {
+				break
+			}
+		}
+	}
+}
+
+// Equals returns true if the map equals the given map, paying attention only to the content of the
+// map and not the order.
+func (o *SliceMap[K, V]) Equals(i MapI[K, V]) bool {
+	return equals[K, V](o, i)
+}
+
+func (o *SliceMap[K, V]) Clear() {
+	if o == nil {
+		return
+	}
+	o.items = nil
+	o.order = nil
+}
+
+func (o *SliceMap[K, V]) IsNil() bool {
+	return o == nil
+}
+
+// String returns the map as a string, in its order.
+func (o *SliceMap[K, V]) String() string {
+	return format(o.Keys(), o.Get)
+}
+
+// Join is just like strings.Join, with the values printed like fmt.Sprint prints them.
+func (o *SliceMap[K, V]) Join(glue string) string {
+	return join(o.Values(), glue)
+}
+
+// join joins the values with glue.
+func join[V any](vals []V, glue string) string {
+	s := make([]string, len(vals))
+	for i, v := range vals {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, glue)
+}
@@ -0,0 +1,101 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+func TestSliceMapSortByValues(t *testing.T) {
+	m := NewSliceMap[string, int]()
+	m.SortByValues()
+	m.Set("c", 3)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("d", 2) // a tie goes after the values it equals
+
+	if s := m.String(); s != `{"a":1,"b":2,"d":2,"c":3}` {
+		t.Errorf("Expected the map sorted by values, got %s", s)
+	}
+
+	m.Set("a", 4) // changing a value moves its key
+	if s := m.String(); s != `{"b":2,"d":2,"c":3,"a":4}` {
+		t.Errorf("Expected the key moved to its new value, got %s", s)
+	}
+
+	if !m.SetChanged("b", 5) || m.SetChanged("b", 5) {
+		t.Error("Expected SetChanged to report only the change")
+	}
+	if s := m.String(); s != `{"d":2,"c":3,"a":4,"b":5}` {
+		t.Errorf("Expected SetChanged to move the key, got %s", s)
+	}
+}
+
+func TestSliceMapSortByKeys(t *testing.T) {
+	m := NewSafeSliceMap[int, string]().SortByKeys()
+	m.Set(3, "c")
+	m.Set(1, "a")
+	m.Set(2, "b")
+
+	if s := m.Join(","); s != "a,b,c" {
+		t.Errorf("Expected the map sorted by keys, got %s", s)
+	}
+	if k := m.GetKeyAt(2); k != 3 {
+		t.Errorf("Expected the last key to be 3, got %d", k)
+	}
+}
+
+type copier struct {
+	items []int
+}
+
+func (c *copier) Copy() *copier {
+	return &copier{append([]int(nil), c.items...)}
+}
+
+func TestSliceMapCopy(t *testing.T) {
+	m := NewSliceMap[string, *copier]()
+	m.Set("a", &copier{[]int{1}})
+	m2 := m.Copy()
+	m2.Get("a").items[0] = 2
+
+	if v := m.Get("a").items[0]; v != 1 {
+		t.Errorf("Expected Copy to copy the values with their Copy method, got %d", v)
+	}
+	if m2.Keys()[0] != "a" {
+		t.Error("Expected the copy to keep the keys")
+	}
+}
+
+func TestSliceMapEncoding(t *testing.T) {
+	m := NewSliceMap[string, float64]()
+	m.Set("z", 1.5)
+	m.Set("y", 2)
+
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	var m2 SafeSliceMap[string, float64]
+	if err := gob.NewDecoder(&b).Decode(&m2); err != nil {
+		t.Fatal(err)
+	}
+	if s := m2.String(); s != `{"z":1.5,"y":2}` {
+		t.Errorf("Expected the order to survive gob, got %s", s)
+	}
+	if !m2.Equals(m) || !m.Equals(&m2) {
+		t.Error("Expected the decoded map to equal the original")
+	}
+}
+
+func TestSliceMapSortPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected sorting values that cannot be compared with < to panic")
+		}
+	}()
+	m := NewSliceMap[string, []int]()
+	m.SortByValues()
+	m.Set("a", []int{1})
+	m.Set("b", []int{2})
+}
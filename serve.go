@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"go/format"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// serveCommand implements "gengen serve", which runs a local web page where a template and its config can be
+// edited side by side, with the output shown as they change.
+func serveCommand(args []string) {
+	var addr, config, delims string
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&addr, "addr", "localhost:8080", "The address to listen on.")
+	fs.StringVar(&config, "c", "", "A config file to start with.")
+	fs.StringVar(&delims, "delims", "", "The delimiters to start with, separated by a space.")
+	fs.BoolVar(&playground.Restricted, "restricted", true, "Run templates like the -restricted option of gengen does. Turn this off to run templates that extend templates outside of their directory.")
+	fs.DurationVar(&playground.Limits.Timeout, "timeout", playground.Limits.Timeout, "The longest time a template can take to execute. Zero means no limit.")
+	fs.Int64Var(&playground.Limits.MaxOutput, "max-output", playground.Limits.MaxOutput, "The largest number of bytes a template can produce. Zero means no limit.")
+	fs.IntVar(&playground.Limits.MaxDepth, "max-depth", playground.Limits.MaxDepth, "The deepest that template calls can be nested. Zero means no limit.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen serve [-addr <address>] [-c <config_file>] [-restricted=false] [<template_file>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// templates that extend others find them relative to the current directory, like on the command line
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	start := renderRequest{Name: filepath.Join(wd, "playground.tmpl"), Config: "{\n}\n", Delims: delims, Options: playgroundOptions()}
+	if fs.NArg() == 1 {
+		start.Name = getRealPath(fs.Arg(0))
+		start.Template = readString(start.Name)
+	}
+	if config != "" {
+		start.Config = readString(getRealPath(config))
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	hosts := listenHosts(addr, l.Addr())
+
+	http.HandleFunc("/", guard(hosts, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := playgroundPage.Execute(w, start); err != nil {
+			log.Print(err)
+		}
+	}))
+	http.HandleFunc("/render", guard(hosts, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST", http.StatusMethodNotAllowed)
+			return
+		}
+		var req renderRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 10<<20)).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Name = start.Name
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(render(req))
+	}))
+	log.Printf("serving the playground at http://%s/", addr)
+	log.Fatal(http.Serve(l, nil))
+}
+
+// listenHosts returns the values of the Host header that name the address the playground listens on. When it
+// listens on localhost, or on all addresses, the loopback names are among them.
+func listenHosts(addr string, listening net.Addr) map[string]bool {
+	host, _, _ := net.SplitHostPort(addr)
+	_, port, _ := net.SplitHostPort(listening.String())
+	names := []string{host}
+	if ip := net.ParseIP(host); host == "" || host == "localhost" || ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+		names = append(names, "localhost", "127.0.0.1", "::1")
+	}
+	hosts := make(map[string]bool)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		hosts[strings.ToLower(net.JoinHostPort(name, port))] = true
+		if port == "80" {
+			hosts[strings.ToLower(strings.TrimSuffix(net.JoinHostPort(name, port), ":80"))] = true
+		}
+	}
+	return hosts
+}
+
+// guard refuses the requests that do not come from the playground page. A web page on another site, which
+// the browser running the playground has open, can send requests to it, and with DNS rebinding can name
+// another host in them. Those requests name another host in their Host or Origin header.
+func guard(hosts map[string]bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ok := hosts[strings.ToLower(r.Host)]
+		if origin := r.Header.Get("Origin"); ok && origin != "" {
+			u, err := url.Parse(origin)
+			ok = err == nil && u.Scheme == "http" && hosts[strings.ToLower(u.Host)]
+		}
+		if !ok {
+			http.Error(w, "the playground only answers requests from its own page", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func readString(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(data)
+}
+
+// A renderRequest is a template and config to render in the playground.
+type renderRequest struct {
+	// Name is the path of the template file, which templates it extends are found relative to.
+	Name     string `json:"-"`
+	Template string `json:"template"`
+	Config   string `json:"config"`
+	Delims   string `json:"delims"`
+	// Options describes the options the playground runs templates with, like they are given on the command line.
+	Options string `json:"-"`
+}
+
+// A renderResponse is the result of rendering a renderRequest.
+type renderResponse struct {
+	// Output is the output of the template, as the command line writes it to stdout.
+	Output string `json:"output"`
+	// Formatted is the output formatted like gofmt, if it is go code.
+	Formatted string `json:"formatted"`
+	// Error is the error that stopped the template, if any.
+	Error string `json:"error"`
+	// FormatError is the reason the output could not be formatted.
+	FormatError string `json:"formatError"`
+	// Trace is the report of the actions that were executed, like the -trace option writes.
+	Trace string `json:"trace"`
+}
+
+// playground are the options that templates run with in the playground. By default they are restricted, with
+// limits that keep a template that is still being written from running away, like a range over a huge number
+// or a template that calls itself.
+var playground = gengen.Options{Restricted: true, Limits: gengen.Limits{Timeout: 5 * time.Second, MaxOutput: 10 << 20, MaxDepth: 1000}}
+
+// playgroundOptions describes the options of the playground as the options of the command line that give
+// the same output, so that a difference from a run of gengen is plain to see.
+func playgroundOptions() string {
+	var opts []string
+	if playground.Restricted {
+		opts = append(opts, "-restricted")
+	}
+	if l := playground.Limits; l.Timeout > 0 {
+		opts = append(opts, "-timeout "+l.Timeout.String())
+	}
+	if l := playground.Limits; l.MaxOutput > 0 {
+		opts = append(opts, fmt.Sprintf("-max-output %d", l.MaxOutput))
+	}
+	if l := playground.Limits; l.MaxDepth > 0 {
+		opts = append(opts, fmt.Sprintf("-max-depth %d", l.MaxDepth))
+	}
+	if opts == nil {
+		return "none"
+	}
+	return strings.Join(opts, " ")
+}
+
+// render executes a template with a config the way the command line does, processors included, and traces it.
+func render(req renderRequest) (resp renderResponse) {
+	dot, err := gengen.ParseConfig([]byte(req.Config))
+	if err != nil {
+		resp.Error = "config: " + err.Error()
+		return
+	}
+	job := gengen.Job{Template: req.Name, Config: "config", Delims: req.Delims}
+	p, err := job.Prepare(dot, req.Template, playground)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+
+	var buf, report bytes.Buffer
+	tracer := gengen.NewTracer(&buf)
+	probes := p.Parsed.Instrument(tracer)
+	_, err = p.Execute(tracer, false)
+	// once Execute returns, the tracer is told nothing more, even if the template took too long and is still
+	// stopping, so the trace can be reported
+	tracer.Report(&report, probes)
+	resp.Output, resp.Trace = buf.String(), report.String()
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	out, err := gengen.Assemble("", []gengen.Part{{Name: job.Template, Out: buf.Bytes()}}, p.Processors)
+	if err != nil {
+		resp.Error = err.Error()
+		return
+	}
+	resp.Output = string(out)
+	if src, err := format.Source(out); err != nil {
+		resp.FormatError = err.Error()
+	} else {
+		resp.Formatted = string(src)
+	}
+	return
+}
+
+var playgroundPage = template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gengen playground</title>
+<style>
+body { margin: 0; font-family: sans-serif; display: grid; grid-template-columns: 1fr 1fr; grid-template-rows: auto 1fr 1fr; height: 100vh; }
+header { grid-column: 1 / 3; padding: 6px 10px; background: #eee; }
+section { display: flex; flex-direction: column; min-height: 0; border: 1px solid #ccc; }
+h2 { font-size: 13px; margin: 0; padding: 4px 8px; background: #f6f6f6; }
+textarea, pre { flex: 1; margin: 0; padding: 6px; font: 13px monospace; border: 0; overflow: auto; tab-size: 4; resize: none; }
+#error { color: #b00; flex: 0 0 auto; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<header>
+<b>gengen playground</b> &mdash; {{.Name}}
+<label>delims <input id="delims" size="6" value="{{.Delims}}"></label>
+<label><input id="raw" type="checkbox"> show the output without gofmt</label>
+<small>options: {{.Options}}</small>
+</header>
+<section><h2>Template</h2><textarea id="template" spellcheck="false">{{.Template}}</textarea></section>
+<section><h2>Output</h2><pre id="error"></pre><pre id="output"></pre></section>
+<section><h2>Config</h2><textarea id="config" spellcheck="false">{{.Config}}</textarea></section>
+<section><h2>Trace</h2><pre id="trace"></pre></section>
+<script>
+var timer, last;
+function render() {
+	var req = {
+		template: document.getElementById("template").value,
+		config: document.getElementById("config").value,
+		delims: document.getElementById("delims").value
+	};
+	fetch("/render", {method: "POST", body: JSON.stringify(req)}).then(function (r) { return r.json(); }).then(function (resp) {
+		last = resp;
+		show();
+	});
+}
+function show() {
+	var resp = last, raw = document.getElementById("raw").checked;
+	var err = resp.error || (raw ? "" : resp.formatError && "gofmt: " + resp.formatError);
+	document.getElementById("error").textContent = err;
+	document.getElementById("output").textContent = raw || !resp.formatted ? resp.output : resp.formatted;
+	document.getElementById("trace").textContent = resp.trace;
+}
+function changed() {
+	clearTimeout(timer);
+	timer = setTimeout(render, 300);
+}
+["template", "config", "delims"].forEach(function (id) { document.getElementById(id).addEventListener("input", changed); });
+document.getElementById("raw").addEventListener("change", function () { if (last) show(); });
+document.querySelectorAll("textarea").forEach(function (t) {
+	t.addEventListener("keydown", function (e) {
+		if (e.key === "Tab") {
+			e.preventDefault();
+			document.execCommand("insertText", false, "\t");
+		}
+	});
+});
+render();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,9 @@
+package templates
+
+import "embed"
+
+// Library holds the template collections of the gengen Library, so that gengen can use them in projects
+// that do not require the gengen module.
+//
+//go:embed map_src/*.tmpl
+var Library embed.FS
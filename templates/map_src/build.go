@@ -27,3 +27,28 @@ package templates
 //go:generate gengen -c safe_test.json  -o ../../pkg/maps/safemap_test.go string_interface_test.tmpl
 //go:generate gengen -c standard_test.json  -o ../../pkg/maps/slicemap_test.go string_interface_slice_test.tmpl
 //go:generate gengen -c safe_test.json  -o ../../pkg/maps/safeslicemap_test.go string_interface_slice_test.tmpl
+
+// The maps of pkg/maps/generic/compat are aliases of the generic maps, with the same names as the maps above, and are
+// tested with the same tests to show that code written for the generated maps keeps working with the generic ones.
+
+//go:generate gengen -c compat_string_string.json  -o ../../pkg/maps/generic/compat/strmapi.go mapi.tmpl
+//go:generate gengen -c compat_string_string.json  -o ../../pkg/maps/generic/compat/strmap.go standard_map.tmpl
+//go:generate gengen -c compat_safe_string_string.json  -o ../../pkg/maps/generic/compat/safestrmap.go standard_map.tmpl
+//go:generate gengen -c compat_string_string.json  -o ../../pkg/maps/generic/compat/strslicemap.go slice_map.tmpl
+//go:generate gengen -c compat_safe_string_string.json  -o ../../pkg/maps/generic/compat/safestrslicemap.go slice_map.tmpl
+
+//go:generate gengen -c compat_standard_test.json  -o ../../pkg/maps/generic/compat/strmap_test.go string_string_test.tmpl
+//go:generate gengen -c compat_safe_test.json  -o ../../pkg/maps/generic/compat/safestrmap_test.go string_string_test.tmpl
+//go:generate gengen -c compat_standard_test.json  -o ../../pkg/maps/generic/compat/strslicemap_test.go string_string_slice_test.tmpl
+//go:generate gengen -c compat_safe_test.json  -o ../../pkg/maps/generic/compat/safestrslicemap_test.go string_string_slice_test.tmpl
+
+//go:generate gengen -c compat_string_interface.json  -o ../../pkg/maps/generic/compat/mapi.go mapi.tmpl
+//go:generate gengen -c compat_string_interface.json  -o ../../pkg/maps/generic/compat/map.go standard_map.tmpl
+//go:generate gengen -c compat_safe_string_interface.json  -o ../../pkg/maps/generic/compat/safemap.go standard_map.tmpl
+//go:generate gengen -c compat_string_interface.json  -o ../../pkg/maps/generic/compat/slicemap.go slice_map.tmpl
+//go:generate gengen -c compat_safe_string_interface.json  -o ../../pkg/maps/generic/compat/safeslicemap.go slice_map.tmpl
+
+//go:generate gengen -c compat_standard_test.json  -o ../../pkg/maps/generic/compat/map_test.go string_interface_test.tmpl
+//go:generate gengen -c compat_safe_test.json  -o ../../pkg/maps/generic/compat/safemap_test.go string_interface_test.tmpl
+//go:generate gengen -c compat_standard_test.json  -o ../../pkg/maps/generic/compat/slicemap_test.go string_interface_slice_test.tmpl
+//go:generate gengen -c compat_safe_test.json  -o ../../pkg/maps/generic/compat/safeslicemap_test.go string_interface_slice_test.tmpl
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templatizeCommand implements "gengen templatize", which makes a draft template and config file from a go file.
+func templatizeCommand(args []string) {
+	var file, tmplFile, configFile string
+	var replaces gengen.ListFlag
+	fs := flag.NewFlagSet("templatize", flag.ExitOnError)
+	fs.StringVar(&file, "file", "", "The go file to make the template from.")
+	fs.Var(&replaces, "replace", "A word in the identifiers of the file and the config key to replace it with, given as Word=key. Repeat for more words.")
+	fs.StringVar(&tmplFile, "o", "", "The template file to write. Defaults to the name of the go file with a .tmpl extension.")
+	fs.StringVar(&configFile, "c", "", "The config file to write. Defaults to the name of the go file with a .json extension.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen templatize -file <go_file> [-replace Word=key...] [-o <template_file>] [-c <config_file>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if file == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	file = getRealPath(file)
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	if tmplFile == "" {
+		tmplFile = base + ".tmpl"
+	}
+	if configFile == "" {
+		configFile = base + ".json"
+	}
+	tmplFile, configFile = getRealPath(tmplFile), getRealPath(configFile)
+
+	var replacements []gengen.Replacement
+	for _, r := range replaces {
+		i := strings.Index(r, "=")
+		if i <= 0 {
+			log.Fatalf("replacement %q must be given as Word=key", r)
+		}
+		replacements = append(replacements, gengen.Replacement{Word: r[:i], Key: r[i+1:]})
+	}
+
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	text, config, err := gengen.Templatize(file, src, replacements)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	comment := fmt.Sprintf("/*\nThis config file makes %s reproduce %s.\n*/\n", filepath.Base(tmplFile), filepath.Base(file))
+	data = append(append([]byte(comment), data...), '\n')
+
+	for _, path := range []string{tmplFile, configFile} {
+		if _, err = os.Stat(path); err == nil {
+			log.Fatalf("%s already exists, so choose another file with the -o or -c option", path)
+		}
+	}
+	if err = gengen.WriteFile(tmplFile, []byte(text)); err != nil {
+		log.Fatal(err)
+	}
+	if err = gengen.WriteFile(configFile, data); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// vendorCommand implements "gengen vendor", which copies the templates and other files that the manifests and
+// go:generate lines of a project use from outside of it into the project, so that they are pinned to the
+// versions copied.
+func vendorCommand(args []string) {
+	var manifest, dir string
+	fs := flag.NewFlagSet("vendor", flag.ExitOnError)
+	fs.StringVar(&manifest, "m", "", "A manifest to vendor the files of, instead of the go:generate lines of the project.")
+	fs.StringVar(&dir, "dir", "gengen_vendor", "The directory to copy the files to, relative to the project directory.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen vendor [-m <manifest>] [-dir <directory>] [<project_directory>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	project := "."
+	if fs.NArg() == 1 {
+		project = fs.Arg(0)
+	}
+	project = getRealPath(project)
+	v := &gengen.Vendor{Project: project, Dir: filepath.Join(project, dir)}
+
+	report := func(path string, changed bool, err error) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		if changed {
+			fmt.Println("updated", path)
+		}
+	}
+	if manifest != "" {
+		path := getRealPath(manifest)
+		changed, err := v.Manifest(path)
+		report(path, changed, err)
+	} else {
+		err := walkPackages(project, func(d string) error {
+			if d == v.Dir {
+				return filepath.SkipDir
+			}
+			files, err := filepath.Glob(filepath.Join(d, "*.go"))
+			for _, path := range files {
+				changed, err := v.GoFile(path)
+				report(path, changed, err)
+			}
+			return err
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(v.Dir); os.IsNotExist(err) {
+		fmt.Println("nothing to vendor")
+		return
+	}
+	if err := v.Lock(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/goradd/gengen/pkg/gengen"
+	"os"
+	"sort"
+)
+
+// versionCommand implements "gengen version", which reports the version of gengen and the features that
+// templates can require of it.
+func versionCommand(args []string) {
+	var short bool
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	fs.BoolVar(&short, "short", false, "Print only the version number.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gengen version [-short]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if short {
+		fmt.Println(gengen.Version)
+		return
+	}
+
+	fmt.Println("gengen", gengen.Version)
+	fmt.Println("features:")
+	var names []string
+	width := 0
+	for name := range gengen.Features {
+		names = append(names, name)
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-*s  %s\n", width, name, gengen.Features[name])
+	}
+}